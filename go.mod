@@ -7,7 +7,6 @@ toolchain go1.23.4
 require (
 	github.com/chromedp/cdproto v0.0.0-20250120090109-d38428e4d9c8
 	github.com/chromedp/chromedp v0.12.1
-	github.com/desertbit/fillpdf v0.0.2
 )
 
 require (
@@ -0,0 +1,188 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Layout of addendum pages: letter size with one-inch margins, 10pt Helvetica.
+const (
+	addendumMargin    = 72
+	addendumTop       = 792 - addendumMargin
+	addendumLeading   = 14
+	addendumLines     = (792 - 2*addendumMargin) / addendumLeading
+	addendumLineChars = 90 // Approximate characters of Helvetica at 10pt that fit between the margins
+)
+
+// WithOverflowAddendum keeps text fields within their maximum length when the form
+// is saved: text beyond a field's MaxLength is cut, at a space where possible, and
+// printed under the field's name on addendum pages appended to the output. Fields
+// without a maximum length are written in full. Addendum text is rendered in
+// Helvetica, so it is limited to characters in the standard Latin character set.
+func WithOverflowAddendum() Option {
+	return func(o *Options) {
+		o.OverflowAddendum = true
+	}
+}
+
+// overflow is the text of a field that did not fit within its maximum length.
+type overflow struct {
+	field string
+	text  string
+}
+
+// splitOverflow splits the output value of a text field at its maximum length
+// when WithOverflowAddendum is set, and reports whether it was split.
+func (f *PDFForm) splitOverflow(field Field, value string) (kept, rest string, overflowed bool) {
+	if !f.options.OverflowAddendum || field.Type != Text || field.MaxLength <= 0 {
+		return value, "", false
+	}
+	runes := []rune(value)
+	if len(runes) <= field.MaxLength {
+		return value, "", false
+	}
+
+	// Break at the last space that fits, unless that leaves the field mostly empty
+	cut := field.MaxLength
+	for i := cut; i > cut/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace),
+		strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace), true
+}
+
+// overflows returns the overflowing text of each field, in document order.
+func (f *PDFForm) overflows() []overflow {
+	var overflows []overflow
+	for _, name := range f.order {
+		field, exists := f.fields[name]
+		if !exists || field.Value == nil {
+			continue
+		}
+		// Values that can't be written are reported when the form is filled
+		value, err := f.outputValue(field)
+		if err != nil {
+			continue
+		}
+		if _, rest, overflowed := f.splitOverflow(field, value); overflowed {
+			overflows = append(overflows, overflow{field: name, text: rest})
+		}
+	}
+	return overflows
+}
+
+// appendAddendum appends addendum pages holding overflows to the PDF at path in place.
+func (f *PDFForm) appendAddendum(ctx context.Context, path string, overflows []overflow) error {
+	addendum, err := os.CreateTemp("", "addendum-*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create addendum file: %w", err)
+	}
+	defer f.options.removeTemp(addendum.Name())
+
+	_, err = addendum.Write(addendumPDF(overflows))
+	addendum.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write addendum file: %w", err)
+	}
+
+	err = f.options.rewritePDF(ctx, path, func(in, out string) []string {
+		return []string{"A=" + in, "B=" + addendum.Name(), "cat", "A", "B", "output", out}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append addendum: %w", err)
+	}
+	return nil
+}
+
+// addendumLine is a line of addendum text, bold for headings.
+type addendumLine struct {
+	text string
+	bold bool
+}
+
+// addendumPDF lays out overflows on as many letter-sized pages as needed.
+func addendumPDF(overflows []overflow) []byte {
+	lines := []addendumLine{{text: "Addendum", bold: true}, {}}
+	for _, o := range overflows {
+		lines = append(lines, addendumLine{text: fmt.Sprintf("Continued from field %s:", o.field), bold: true})
+		for _, line := range wrapText(o.text, addendumLineChars) {
+			lines = append(lines, addendumLine{text: line})
+		}
+		lines = append(lines, addendumLine{})
+	}
+
+	var pages [][]addendumLine
+	for len(lines) > 0 {
+		n := min(len(lines), addendumLines)
+		pages = append(pages, lines[:n])
+		lines = lines[n:]
+	}
+
+	// Objects 1 to 4 are the catalog, page tree and fonts, followed by a page and
+	// its content stream for each page
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold >>",
+	}
+	for i, page := range pages {
+		var content strings.Builder
+		for j, line := range page {
+			if line.text == "" {
+				continue
+			}
+			font := "F1"
+			if line.bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s 10 Tf %d %d Td (%s) Tj ET\n",
+				font, addendumMargin, addendumTop-addendumLeading*(j+1), fdfEscaper.Replace(line.text))
+		}
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", 6+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+	return buildPDF(objects)
+}
+
+// wrapText breaks text into lines of at most width characters at spaces, keeping
+// its line breaks and splitting words longer than a line.
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line []rune
+		for _, word := range strings.Fields(paragraph) {
+			runes := []rune(word)
+			for len(runes) > width {
+				if len(line) > 0 {
+					lines = append(lines, string(line))
+					line = nil
+				}
+				lines = append(lines, string(runes[:width]))
+				runes = runes[width:]
+			}
+			if len(line) > 0 && len(line)+1+len(runes) > width {
+				lines = append(lines, string(line))
+				line = nil
+			}
+			if len(line) > 0 {
+				line = append(line, ' ')
+			}
+			line = append(line, runes...)
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
@@ -0,0 +1,105 @@
+package pdfprocessor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		field    string
+		value    string
+		wantKept string
+		wantRest string
+	}{
+		{name: "fits", field: "notes", value: "short note", wantKept: "short note"},
+		{name: "exactly max length", field: "notes", value: strings.Repeat("x", 20), wantKept: strings.Repeat("x", 20)},
+		{
+			name:     "cut at last space",
+			field:    "notes",
+			value:    "The quick brown fox jumps over the lazy dog",
+			wantKept: "The quick brown fox",
+			wantRest: "jumps over the lazy dog",
+		},
+		{
+			name:     "no space",
+			field:    "notes",
+			value:    "abcdefghijklmnopqrstuvwxyz",
+			wantKept: "abcdefghijklmnopqrst",
+			wantRest: "uvwxyz",
+		},
+		{
+			name:     "space too early",
+			field:    "notes",
+			value:    "ab cdefghijklmnopqrstuvwxyz",
+			wantKept: "ab cdefghijklmnopqrs",
+			wantRest: "tuvwxyz",
+		},
+		{
+			name:     "counts characters, not bytes",
+			field:    "notes",
+			value:    strings.Repeat("é", 21),
+			wantKept: strings.Repeat("é", 20),
+			wantRest: "é",
+		},
+		{name: "field without max length", field: "amount", value: strings.Repeat("9", 40), wantKept: strings.Repeat("9", 40)},
+		{name: "addendum disabled", opts: []Option{}, field: "notes", value: strings.Repeat("x", 30), wantKept: strings.Repeat("x", 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if opts == nil {
+				opts = []Option{WithOverflowAddendum()}
+			}
+			f := newTestForm(t, testDump, opts...)
+			if err := f.SetField(tt.field, tt.value); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := f.formData()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := data[tt.field]; got != tt.wantKept {
+				t.Errorf("formData()[%s] = %q, want %q", tt.field, got, tt.wantKept)
+			}
+			var want []overflow
+			if tt.wantRest != "" {
+				want = []overflow{{field: tt.field, text: tt.wantRest}}
+			}
+			if got := f.overflows(); !reflect.DeepEqual(got, want) {
+				t.Errorf("overflows() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: []string{""}},
+		{name: "fits", text: "a b c", want: []string{"a b c"}},
+		{name: "wraps at spaces", text: "hello world again", want: []string{"hello", "world", "again"}},
+		{name: "fills lines", text: "aa bb cc dd ee", want: []string{"aa bb cc", "dd ee"}},
+		{name: "collapses spaces", text: "a    b", want: []string{"a b"}},
+		{name: "long word split", text: "abcdefghijklmnop", want: []string{"abcdefghij", "klmnop"}},
+		{name: "long word after short", text: "hi abcdefghijklmno", want: []string{"hi", "abcdefghij", "klmno"}},
+		{name: "keeps line breaks", text: "one\n\ntwo", want: []string{"one", "", "two"}},
+		{name: "multibyte", text: "ééééé ééééé", want: []string{"ééééé", "ééééé"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.text, 10); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText(%q, 10) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
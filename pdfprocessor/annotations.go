@@ -0,0 +1,80 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Annotation is an annotation on a page of the form, such as a link, a comment or
+// the widget of a form field.
+type Annotation struct {
+	Type string     // Annotation subtype, e.g. "Link", "Text" or "Widget"
+	Page int        // Number of the page showing the annotation, from 1
+	Rect [4]float64 // Lower-left x and y and upper-right x and y, in points
+}
+
+var (
+	subtypePattern = regexp.MustCompile(`/Subtype\s*/(\w+)`)
+	rectPattern    = regexp.MustCompile(`/Rect\s*\[([^\]]*)\]`)
+)
+
+// GetAnnotations returns the annotations of the template in page order. pdftk's
+// dump_data_annots reports only link annotations, so they are read from an
+// uncompressed copy of the template made with pdftk instead. Templates that store
+// objects in compressed object streams return ErrObjectStreams.
+func (f *PDFForm) GetAnnotations() ([]Annotation, error) {
+	objects, err := f.options.readObjects(context.Background(), f.inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations: %w", err)
+	}
+	return objects.annotations(), nil
+}
+
+// annotations returns the annotations of the pages of p in page order.
+func (p pdfObjects) annotations() []Annotation {
+	var annotations []Annotation
+	for i, page := range p.pages() {
+		for _, key := range p.pageAnnots(page) {
+			body := p[key]
+			subtype := subtypePattern.FindStringSubmatch(body)
+			if subtype == nil {
+				continue
+			}
+			annotation := Annotation{Type: subtype[1], Page: i + 1}
+			if rect := rectPattern.FindStringSubmatch(body); rect != nil {
+				for j, value := range strings.Fields(rect[1]) {
+					if j < len(annotation.Rect) {
+						annotation.Rect[j], _ = strconv.ParseFloat(value, 64)
+					}
+				}
+			}
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// RemoveAnnotations removes the annotations of the given types, e.g. "Link" or
+// "Text", from the output when the form is saved. Removal runs after filling, so
+// removing "Widget" annotations drops any fields left unflattened. pdftk cannot
+// remove annotations, so pdfcpu must be installed. Call it with no types to keep
+// all annotations again.
+func (f *PDFForm) RemoveAnnotations(types ...string) {
+	f.removed = types
+	f.pdfData = nil
+}
+
+// removeAnnotations removes the annotation types selected with RemoveAnnotations
+// from the PDF at path in place.
+func (f *PDFForm) removeAnnotations(ctx context.Context, path string) error {
+	return rewriteFile(path, func(in, out string) error {
+		args := append([]string{"annotations", "remove", in, out}, f.removed...)
+		if _, err := f.options.runCommand(ctx, "pdfcpu", args...); err != nil {
+			return fmt.Errorf("failed to remove annotations: %w", err)
+		}
+		return nil
+	})
+}
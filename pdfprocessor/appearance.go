@@ -0,0 +1,102 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldAppearance is a field's default appearance: the font, size and color its
+// text is drawn with.
+type FieldAppearance struct {
+	Font  string    // Font resource name, e.g. "Helv"
+	Size  float64   // Font size in points, 0 if sized automatically
+	Color []float64 // Color components: one for gray, three for RGB, four for CMYK
+}
+
+// daPattern matches the default appearance string of a field or AcroForm dictionary.
+var daPattern = regexp.MustCompile(`/DA\s*\(((?:[^()\\]|\\.)*)\)`)
+
+// GetFieldAppearance returns the default appearance (DA) of a field, falling back
+// to the form-wide default when the field doesn't set its own. The appearance is
+// read from an uncompressed copy of the template made with pdftk; fields whose
+// names are stored in hex or Unicode strings are not found, and templates that
+// store objects in compressed object streams return ErrObjectStreams.
+func (f *PDFForm) GetFieldAppearance(name string) (FieldAppearance, error) {
+	if _, exists := f.fields[name]; !exists {
+		return FieldAppearance{}, fmt.Errorf("field %s not found", name)
+	}
+	pdfName := name
+	if original, ok := f.pdfNames[name]; ok {
+		pdfName = original
+	}
+
+	objects, err := f.options.readObjects(context.Background(), f.inputPath)
+	if err != nil {
+		return FieldAppearance{}, fmt.Errorf("failed to read field appearance: %w", err)
+	}
+
+	da, ok := objects.defaultAppearance(pdfName)
+	if !ok {
+		return FieldAppearance{}, fmt.Errorf("field %s has no default appearance", name)
+	}
+	return parseDefaultAppearance(da), nil
+}
+
+// defaultAppearance looks up the DA string of a field by its fully qualified name,
+// checking the field, then its widgets, then the AcroForm dictionary.
+func (p pdfObjects) defaultAppearance(name string) (string, bool) {
+	var formDefault string
+	for _, body := range p {
+		if strings.Contains(body, "/Fields") {
+			if match := daPattern.FindStringSubmatch(body); match != nil {
+				formDefault = match[1]
+			}
+		}
+		if !titlePattern.MatchString(body) || p.fieldName(body) != name {
+			continue
+		}
+		if match := daPattern.FindStringSubmatch(body); match != nil {
+			return match[1], true
+		}
+		if kids := kidsPattern.FindStringSubmatch(body); kids != nil {
+			for _, kid := range references(kids[1]) {
+				if match := daPattern.FindStringSubmatch(p[kid]); match != nil {
+					return match[1], true
+				}
+			}
+		}
+	}
+	return formDefault, formDefault != ""
+}
+
+// parseDefaultAppearance reads the font, size and color operators of a DA string
+// such as "/Helv 12 Tf 0 0 1 rg".
+func parseDefaultAppearance(da string) FieldAppearance {
+	var appearance FieldAppearance
+	var operands []string
+	for _, token := range strings.Fields(da) {
+		switch token {
+		case "Tf":
+			if len(operands) >= 2 {
+				appearance.Font = strings.TrimPrefix(operands[len(operands)-2], "/")
+				appearance.Size, _ = strconv.ParseFloat(operands[len(operands)-1], 64)
+			}
+		case "g", "rg", "k":
+			components := map[string]int{"g": 1, "rg": 3, "k": 4}[token]
+			if len(operands) >= components {
+				appearance.Color = make([]float64, components)
+				for i, operand := range operands[len(operands)-components:] {
+					appearance.Color[i], _ = strconv.ParseFloat(operand, 64)
+				}
+			}
+		default:
+			operands = append(operands, token)
+			continue
+		}
+		operands = nil
+	}
+	return appearance
+}
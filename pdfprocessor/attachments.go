@@ -0,0 +1,67 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// attachment is a file embedded in the output PDF.
+type attachment struct {
+	name string
+	data []byte
+}
+
+// AttachFile embeds data as a file attachment named name in the output when the
+// form is saved, e.g. a receipt image. Attachments are added after filling and
+// flattening and are listed in a viewer's attachments panel. Attaching a name
+// again replaces the earlier data.
+func (f *PDFForm) AttachFile(name string, data []byte) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("attachment name is empty")
+	}
+	if filepath.Base(name) != name {
+		return fmt.Errorf("attachment name %s must not contain a path", name)
+	}
+
+	f.pdfData = nil
+	for i, a := range f.attached {
+		if a.name == name {
+			f.attached[i].data = data
+			return nil
+		}
+	}
+	f.attached = append(f.attached, attachment{name: name, data: data})
+	return nil
+}
+
+// attachFiles embeds the attachments into the PDF at path in place. pdftk names
+// attachments after their files, so each is written under its name to a
+// temporary directory first.
+func (f *PDFForm) attachFiles(ctx context.Context, path string) error {
+	dir, err := os.MkdirTemp("", "pdf-attachments-*")
+	if err != nil {
+		return fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	defer f.options.removeTemp(dir)
+
+	files := make([]string, 0, len(f.attached))
+	for _, a := range f.attached {
+		file := filepath.Join(dir, a.name)
+		if err := os.WriteFile(file, a.data, 0644); err != nil {
+			return fmt.Errorf("failed to write attachment %s: %w", a.name, err)
+		}
+		files = append(files, file)
+	}
+
+	err = f.options.rewritePDF(ctx, path, func(in, out string) []string {
+		args := append([]string{in, "attach_files"}, files...)
+		return append(args, "output", out)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to attach files: %w", err)
+	}
+	return nil
+}
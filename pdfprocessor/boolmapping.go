@@ -0,0 +1,57 @@
+package pdfprocessor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BooleanMapping is the pair of states written to a checkbox for true and false.
+type BooleanMapping struct {
+	True  string // State written when the box is checked, e.g. "Yes"
+	False string // State written when the box is unchecked, usually "Off"
+}
+
+// WithBooleanMapping sets the states written to a boolean field for true and
+// false, e.g. "Yes" and "No" or "1" and "0", for forms whose checkboxes don't use
+// the states parsed from the template. Radio groups and other fields with several
+// checked states need a mapping to be set to true. It applies to PDF forms only.
+func WithBooleanMapping(fieldName, trueVal, falseVal string) Option {
+	return func(o *Options) {
+		if o.BooleanMappings == nil {
+			o.BooleanMappings = make(map[string]BooleanMapping)
+		}
+		o.BooleanMappings[fieldName] = BooleanMapping{True: trueVal, False: falseVal}
+	}
+}
+
+// boolExport returns the state written to the PDF for a boolean value of field:
+// the mapping set with WithBooleanMapping, else for true the checked state parsed
+// from the template, else On or Off. Radio groups and other fields with several
+// checked states need a mapping to choose one.
+func (f *PDFForm) boolExport(field Field, checked bool) (string, error) {
+	if mapping, ok := f.options.BooleanMappings[field.Name]; ok {
+		if checked {
+			return mapping.True, nil
+		}
+		return mapping.False, nil
+	}
+	if !checked {
+		return boolState(false), nil
+	}
+
+	var states []string
+	for _, option := range field.Options {
+		if option != "Off" {
+			states = append(states, option)
+		}
+	}
+	switch len(states) {
+	case 0:
+		return boolState(true), nil
+	case 1:
+		return states[0], nil
+	default:
+		return "", fmt.Errorf("field %s has several checked states (%s); choose one with WithBooleanMapping",
+			field.Name, strings.Join(states, ", "))
+	}
+}
@@ -0,0 +1,85 @@
+package pdfprocessor
+
+import "testing"
+
+func TestFormDataBooleanStates(t *testing.T) {
+	radio := []string{"Choice1", "Choice2", "Off"}
+	tests := []struct {
+		name    string
+		field   Field
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "checkbox checked state from template",
+			field: Field{Name: "agree", Type: Boolean, Value: true, Options: []string{"Off", "Yes"}},
+			want:  "Yes",
+		},
+		{
+			name:  "checkbox unchecked",
+			field: Field{Name: "agree", Type: Boolean, Value: false, Options: []string{"Off", "Yes"}},
+			want:  "Off",
+		},
+		{
+			name:  "checkbox without parsed states",
+			field: Field{Name: "agree", Type: Boolean, Value: true},
+			want:  "On",
+		},
+		{
+			name:  "mapping for true",
+			field: Field{Name: "agree", Type: Boolean, Value: true, Options: []string{"Off", "1"}},
+			opts:  []Option{WithBooleanMapping("agree", "1", "0")},
+			want:  "1",
+		},
+		{
+			name:  "mapping for false",
+			field: Field{Name: "agree", Type: Boolean, Value: false, Options: []string{"Off", "1"}},
+			opts:  []Option{WithBooleanMapping("agree", "1", "0")},
+			want:  "0",
+		},
+		{
+			name:  "radio with mapping",
+			field: Field{Name: "plan", Type: Boolean, Value: true, Options: radio},
+			opts:  []Option{WithBooleanMapping("plan", "Choice2", "Off")},
+			want:  "Choice2",
+		},
+		{
+			name:  "radio unchecked without mapping",
+			field: Field{Name: "plan", Type: Boolean, Value: false, Options: radio},
+			want:  "Off",
+		},
+		{
+			name:    "radio checked without mapping",
+			field:   Field{Name: "plan", Type: Boolean, Value: true, Options: radio},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &PDFForm{
+				fields:  map[string]Field{tt.field.Name: tt.field},
+				order:   []string{tt.field.Name},
+				options: newOptions(append([]Option{WithLogger(nil)}, tt.opts...)),
+			}
+
+			data, err := f.formData()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("formData() = %v, want error", data)
+				}
+				if f.Validate() == nil {
+					t.Error("Validate() accepted a value formData rejects")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("formData() error = %v", err)
+			}
+			if got := data[tt.field.Name]; got != tt.want {
+				t.Errorf("formData()[%s] = %q, want %q", tt.field.Name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// dependencyProbeTimeout bounds how long a tool may take to report its version
+const dependencyProbeTimeout = 10 * time.Second
+
+// chromeExecutables lists the names and paths searched for a Chrome executable,
+// following the order chromedp uses
+var chromeExecutables = map[string][]string{
+	"darwin": {
+		"/Applications/Chromium.app/Contents/MacOS/Chromium",
+		"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+		"/Applications/Google Chrome Beta.app/Contents/MacOS/Google Chrome Beta",
+		"/Applications/Google Chrome Canary.app/Contents/MacOS/Google Chrome Canary",
+		"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+		"google-chrome",
+		"headless-shell",
+		"headless_shell",
+		"chromium",
+	},
+	"windows": {
+		"chrome",
+		"chrome.exe",
+		`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
+		`C:\Program Files\Google\Chrome\Application\chrome.exe`,
+		`C:\Program Files (x86)\Microsoft\Edge\Application\msedge.exe`,
+		`C:\Program Files\Microsoft\Edge\Application\msedge.exe`,
+	},
+	"default": {
+		"headless_shell",
+		"headless-shell",
+		"chromium",
+		"chromium-browser",
+		"google-chrome",
+		"google-chrome-stable",
+		"google-chrome-beta",
+		"google-chrome-unstable",
+		"/usr/bin/google-chrome",
+		"/usr/local/bin/chrome",
+		"/snap/bin/chromium",
+		"chrome",
+	},
+}
+
+// Dependency describes an external tool used by the package
+type Dependency struct {
+	Name     string // Name of the tool
+	Required bool   // Whether PDF form processing needs the tool
+	Found    bool   // Whether the tool was found
+	Path     string // Path of the executable, if found
+	Version  string // Version reported by the tool, if available
+	Purpose  string // What the tool is used for
+}
+
+// DependencyReport lists the availability of the package's external tools
+type DependencyReport struct {
+	PDFTK       Dependency // pdftk, used to read and fill PDF forms
+	Chrome      Dependency // Chrome or Chromium, used by chromedp to convert HTML forms to PDF
+	PDFToPPM    Dependency // pdftoppm, used to render previews with PreviewPNG
+	Ghostscript Dependency // Ghostscript, used to convert output to PDF/A
+	PDFCPU      Dependency // pdfcpu, used to lock fields selected with FlattenFields and remove annotations
+	MuPDF       Dependency // MuPDF's mutool, used to save incrementally with WithIncrementalSave
+}
+
+// Err returns an error naming any required tools that are missing
+func (r DependencyReport) Err() error {
+	var missing []string
+	for _, dep := range []Dependency{r.PDFTK, r.Chrome, r.PDFToPPM, r.Ghostscript, r.PDFCPU, r.MuPDF} {
+		if dep.Required && !dep.Found {
+			missing = append(missing, dep.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required dependencies: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// CheckDependencies probes for the external tools used by the package and
+// reports which are present, along with their paths and versions.
+func CheckDependencies() DependencyReport {
+	report := DependencyReport{
+		PDFTK: Dependency{
+			Name:     "pdftk",
+			Required: true,
+			Purpose:  "reading and filling PDF forms",
+		},
+		Chrome: Dependency{
+			Name:    "chrome",
+			Purpose: "converting HTML forms to PDF",
+		},
+		PDFToPPM: Dependency{
+			Name:    "pdftoppm",
+			Purpose: "rendering page previews",
+		},
+		Ghostscript: Dependency{
+			Name:    "gs",
+			Purpose: "converting output to PDF/A",
+		},
+		PDFCPU: Dependency{
+			Name:    "pdfcpu",
+			Purpose: "locking individual fields and removing annotations",
+		},
+		MuPDF: Dependency{
+			Name:    "mutool",
+			Purpose: "saving incrementally to preserve signatures",
+		},
+	}
+
+	if path, err := exec.LookPath("pdftk"); err == nil {
+		report.PDFTK.Found = true
+		report.PDFTK.Path = path
+		report.PDFTK.Version = toolVersion(path, "--version")
+	}
+
+	if path, err := exec.LookPath("pdftoppm"); err == nil {
+		report.PDFToPPM.Found = true
+		report.PDFToPPM.Path = path
+		report.PDFToPPM.Version = toolVersion(path, "-v")
+	}
+
+	if path, err := exec.LookPath("gs"); err == nil {
+		report.Ghostscript.Found = true
+		report.Ghostscript.Path = path
+		report.Ghostscript.Version = toolVersion(path, "--version")
+	}
+
+	if path, err := exec.LookPath("pdfcpu"); err == nil {
+		report.PDFCPU.Found = true
+		report.PDFCPU.Path = path
+		report.PDFCPU.Version = toolVersion(path, "version")
+	}
+
+	if path, err := exec.LookPath("mutool"); err == nil {
+		report.MuPDF.Found = true
+		report.MuPDF.Path = path
+		report.MuPDF.Version = toolVersion(path, "-v")
+	}
+
+	if path, found := findChrome(); found {
+		report.Chrome.Found = true
+		report.Chrome.Path = path
+		// Chrome on Windows doesn't print its version to the console
+		if runtime.GOOS != "windows" {
+			report.Chrome.Version = toolVersion(path, "--version")
+		}
+	}
+
+	return report
+}
+
+// findChrome returns the path of the first Chrome executable found, searching
+// the same locations as chromedp.
+func findChrome() (string, bool) {
+	candidates, ok := chromeExecutables[runtime.GOOS]
+	if !ok {
+		candidates = chromeExecutables["default"]
+	}
+	for _, name := range candidates {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// toolVersion runs a tool with the given arguments and returns the first
+// non-empty line of its output, or an empty string if it fails.
+func toolVersion(path string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), dependencyProbeTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package pdfprocessor
+
+import "sort"
+
+// IsDirty reports whether any field has been set since the form was loaded or
+// last saved or uploaded, e.g. to skip re-rendering untouched documents. Setting
+// a field to the value it already has still marks it. Defaults applied while
+// loading don't.
+func (f *PDFForm) IsDirty() bool {
+	return len(f.dirty) > 0
+}
+
+// DirtyFields returns the names of the fields set since the form was loaded or
+// last saved or uploaded, sorted by name.
+func (f *PDFForm) DirtyFields() []string {
+	names := make([]string, 0, len(f.dirty))
+	for name := range f.dirty {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// markDirty records that a field was set.
+func (f *PDFForm) markDirty(name string) {
+	if f.dirty == nil {
+		f.dirty = make(map[string]bool)
+	}
+	f.dirty[name] = true
+}
+
+// markClean forgets the fields set so far, after the form is loaded, saved or uploaded.
+func (f *PDFForm) markClean() {
+	f.dirty = nil
+}
@@ -0,0 +1,62 @@
+package pdfprocessor
+
+import (
+	"context"
+	"io"
+)
+
+// Downloader fetches the content of a form from a URL, e.g. over HTTP or from an
+// object store such as S3. The caller closes the returned reader.
+type Downloader interface {
+	Download(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+// WithDownloader sets the Downloader used by NewFormFromURL and NewHTMLFormFromURL
+// instead of the default HTTP download. The maximum download size still applies;
+// fetch headers, the HTTP client and the redirect policy do not.
+func WithDownloader(downloader Downloader) Option {
+	return func(o *Options) {
+		o.Downloader = downloader
+	}
+}
+
+// httpDownloader is the default Downloader, a GET request with the configured
+// headers, HTTP client and redirect policy.
+type httpDownloader struct {
+	options Options
+}
+
+// Download implements Downloader.
+func (d httpDownloader) Download(ctx context.Context, url string) (io.ReadCloser, error) {
+	resp, err := fetch(ctx, url, d.options)
+	if err != nil {
+		return nil, err
+	}
+	return &download{Reader: resp.Body, Closer: resp.Body, contentType: resp.Header.Get("Content-Type")}, nil
+}
+
+// download is a downloaded body, limited to the maximum download size.
+type download struct {
+	io.Reader
+	io.Closer
+	contentType string // Media type reported by the source, if known
+}
+
+// download fetches url with the configured Downloader.
+func (o Options) download(ctx context.Context, url string) (*download, error) {
+	var downloader Downloader = httpDownloader{options: o}
+	if o.Downloader != nil {
+		downloader = o.Downloader
+	}
+
+	body, err := downloader.Download(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := body.(*download)
+	if !ok {
+		d = &download{Reader: body, Closer: body}
+	}
+	d.Reader = limitDownload(d.Reader, o.MaxDownloadSize)
+	return d, nil
+}
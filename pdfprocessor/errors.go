@@ -0,0 +1,75 @@
+package pdfprocessor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrPDFNotGenerated is returned by HTMLForm.Upload when GeneratePDF has not been called
+var ErrPDFNotGenerated = errors.New("PDF not generated: call GeneratePDF before uploading")
+
+// ErrChromeNotFound is returned by HTMLForm.GeneratePDF when no Chrome or Chromium
+// executable is installed and no fallback renderer is configured
+var ErrChromeNotFound = errors.New("chrome not found: install Google Chrome or Chromium to convert HTML forms to PDF")
+
+// ErrIncrementalRewrite is returned when saving a form with WithIncrementalSave
+// that is also configured to rewrite the document
+var ErrIncrementalRewrite = errors.New("options that rewrite the document cannot be used with incremental saving")
+
+// ErrObjectStreams is returned when reading annotations or field appearances from
+// a PDF that stores objects in compressed object streams, which can't be read
+var ErrObjectStreams = errors.New("PDF stores objects in compressed object streams, which cannot be read")
+
+// ErrSetFields reports the fields that could not be set by SetFields
+type ErrSetFields struct {
+	UnmatchedFields []string          // Names that did not match any field in the form
+	TypeMismatches  map[string]string // Rejected values, keyed by name, with the reason
+}
+
+func (e *ErrSetFields) Error() string {
+	var errors []string
+	for _, name := range e.UnmatchedFields {
+		errors = append(errors, fmt.Sprintf("field '%s' not found", name))
+	}
+
+	names := make([]string, 0, len(e.TypeMismatches))
+	for name := range e.TypeMismatches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errors = append(errors, fmt.Sprintf("field '%s': %s", name, e.TypeMismatches[name]))
+	}
+
+	return fmt.Sprintf("failed to set some fields: %s", strings.Join(errors, "; "))
+}
+
+// add records a value rejected by SetField
+func (e *ErrSetFields) add(name string, err error) {
+	if e.TypeMismatches == nil {
+		e.TypeMismatches = make(map[string]string)
+	}
+	e.TypeMismatches[name] = err.Error()
+}
+
+// orNil returns the error if any field failed, or nil otherwise
+func (e *ErrSetFields) orNil() error {
+	if len(e.UnmatchedFields) == 0 && len(e.TypeMismatches) == 0 {
+		return nil
+	}
+	sort.Strings(e.UnmatchedFields)
+	return e
+}
+
+// ErrCommandTimeout reports a pdftk invocation that was killed after exceeding its timeout
+type ErrCommandTimeout struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (e *ErrCommandTimeout) Error() string {
+	return fmt.Sprintf("command timed out after %s: %s", e.Timeout, e.Command)
+}
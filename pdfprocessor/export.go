@@ -0,0 +1,64 @@
+package pdfprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Formats supported by ExportValues
+const (
+	ExportJSON       = "json"       // A JSON object mapping field names to values
+	ExportURLEncoded = "urlencoded" // application/x-www-form-urlencoded name=value pairs
+	ExportFDF        = "fdf"        // An FDF document as used to fill the PDF, for PDF forms only
+)
+
+// ExportValues writes the set field values to w as JSON, form-urlencoded data or
+// FDF, for persistence or interchange. FDF output uses the template's field names
+// and checkbox states, and can be loaded into the form by PDF viewers.
+func (f *PDFForm) ExportValues(format string, w io.Writer) error {
+	if format == ExportFDF {
+		data, err := f.formData()
+		if err != nil {
+			return fmt.Errorf("failed to export values: %w", err)
+		}
+		if err := writeFDF(w, data); err != nil {
+			return fmt.Errorf("failed to export values: %w", err)
+		}
+		return nil
+	}
+	return exportValues(format, w, f.Values())
+}
+
+// ExportValues writes the set field values to w as JSON or form-urlencoded data
+func (f *HTMLForm) ExportValues(format string, w io.Writer) error {
+	values := make(map[string]interface{})
+	for name, field := range f.GetFields() {
+		if field.Value != nil {
+			values[name] = field.Value
+		}
+	}
+	return exportValues(format, w, values)
+}
+
+// exportValues writes values to w in one of the formats shared by all forms
+func exportValues(format string, w io.Writer, values map[string]interface{}) error {
+	var err error
+	switch format {
+	case ExportJSON:
+		err = json.NewEncoder(w).Encode(values)
+	case ExportURLEncoded:
+		form := make(url.Values, len(values))
+		for name, value := range values {
+			form.Set(name, fmt.Sprint(value))
+		}
+		_, err = io.WriteString(w, form.Encode())
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export values: %w", err)
+	}
+	return nil
+}
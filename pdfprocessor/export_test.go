@@ -0,0 +1,64 @@
+package pdfprocessor
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportValues(t *testing.T) {
+	fdf := fdfHeader + "\n" +
+		"<< /T (agree) /V (Yes)>>\n" +
+		"<< /T (country) /V (ZA)>>\n" +
+		"<< /T (name) /V (Ada Lovelace)>>\n" +
+		fdfFooter + "\n"
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "json",
+			format: ExportJSON,
+			want:   `{"agree":true,"country":"ZA","full_name":"Ada Lovelace"}` + "\n",
+		},
+		{
+			name:   "urlencoded",
+			format: ExportURLEncoded,
+			want:   "agree=true&country=ZA&full_name=Ada+Lovelace",
+		},
+		{
+			// FDF uses the template's field names and checkbox states
+			name:   "fdf",
+			format: ExportFDF,
+			want:   fdf,
+		},
+		{
+			name:    "unsupported",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestForm(t, testDump)
+			if err := f.RenameField("name", "full_name"); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.SetFields(map[string]interface{}{"full_name": "Ada Lovelace", "agree": true, "country": "ZA"}); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			err := f.ExportValues(tt.format, &buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExportValues(%s) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+			if got := buf.String(); !tt.wantErr && got != tt.want {
+				t.Errorf("ExportValues(%s) =\n%s\nwant\n%s", tt.format, got, tt.want)
+			}
+		})
+	}
+}
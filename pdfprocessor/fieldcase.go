@@ -0,0 +1,50 @@
+package pdfprocessor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithCaseInsensitiveFields makes SetField, GetField, ConvertFieldValue,
+// SetFromURLValues and DryRun match field names ignoring case, e.g. "firstname"
+// finds "FirstName", without the fuzzy matching of FindMatchingField. The
+// lowercase index is built when the form is loaded, which fails if two fields
+// differ only in case.
+func WithCaseInsensitiveFields() Option {
+	return func(o *Options) {
+		o.CaseInsensitiveNames = true
+	}
+}
+
+// indexFoldedNames builds the lowercase index of field names used with
+// WithCaseInsensitiveFields.
+func (f *PDFForm) indexFoldedNames() error {
+	folded := make(map[string]string, len(f.fields))
+	for name := range f.fields {
+		key := strings.ToLower(name)
+		if other, ok := folded[key]; ok {
+			return fmt.Errorf("fields %s and %s differ only in case", other, name)
+		}
+		folded[key] = name
+	}
+	f.folded = folded
+	return nil
+}
+
+// resolveName returns the name of the field that name refers to. Without
+// WithCaseInsensitiveFields, or if no field matches, name is returned unchanged.
+func (f *PDFForm) resolveName(name string) string {
+	if _, exists := f.fields[name]; exists || f.folded == nil {
+		return name
+	}
+	if actual, ok := f.folded[strings.ToLower(name)]; ok {
+		return actual
+	}
+	return name
+}
+
+// GetField returns the field with the given name and whether it exists.
+func (f *PDFForm) GetField(name string) (Field, bool) {
+	field, exists := f.fields[f.resolveName(name)]
+	return field, exists
+}
@@ -0,0 +1,63 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+)
+
+// FlattenFields locks only the named fields when the form is filled, leaving the
+// other fields editable for the recipient; by default every field is flattened.
+// Neither pdftk nor pdfcpu can flatten individual fields, so the named fields are
+// made read-only with pdfcpu, which must be installed: their values are shown but
+// can't be changed, though they remain form fields. An empty slice locks nothing,
+// leaving every field editable; pass nil to flatten every field again.
+func (f *PDFForm) FlattenFields(names []string) error {
+	for _, name := range names {
+		if _, exists := f.fields[name]; !exists {
+			return fmt.Errorf("field %s not found", name)
+		}
+	}
+	f.locked = nil
+	if names != nil {
+		// Non-nil even when empty, since nil flattens the whole form
+		f.locked = make([]string, 0, len(names))
+	}
+	for _, name := range names {
+		if pdfName, ok := f.pdfNames[name]; ok {
+			name = pdfName
+		}
+		f.locked = append(f.locked, name)
+	}
+	f.pdfData = nil
+	return nil
+}
+
+// fill fills the form into out, flattening every field or locking the fields
+// selected with FlattenFields, or appends the values without flattening with
+// WithIncrementalSave.
+func (f *PDFForm) fill(ctx context.Context, out string) error {
+	if f.options.IncrementalSave {
+		if err := f.checkIncremental(); err != nil {
+			return err
+		}
+		return f.fillIncremental(ctx, out)
+	}
+	data, err := f.formData()
+	if err != nil {
+		return fmt.Errorf("failed to fill PDF: %w", err)
+	}
+	if err := f.options.fillForm(ctx, data, f.inputPath, out, f.locked == nil); err != nil {
+		return fmt.Errorf("failed to fill PDF: %w", err)
+	}
+	if len(f.locked) == 0 {
+		return nil
+	}
+
+	return rewriteFile(out, func(in, tmp string) error {
+		args := append([]string{"form", "lock", in, tmp}, f.locked...)
+		if _, err := f.options.runCommand(ctx, "pdfcpu", args...); err != nil {
+			return fmt.Errorf("failed to lock fields: %w", err)
+		}
+		return nil
+	})
+}
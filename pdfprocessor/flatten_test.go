@@ -0,0 +1,32 @@
+package pdfprocessor
+
+import "testing"
+
+func TestFlattenFields(t *testing.T) {
+	f := &PDFForm{fields: map[string]Field{"name": {Name: "name", Type: Text}}}
+
+	if err := f.FlattenFields([]string{}); err != nil {
+		t.Fatalf("FlattenFields(empty) error = %v", err)
+	}
+	if f.locked == nil || len(f.locked) != 0 {
+		t.Errorf("locked = %#v after FlattenFields(empty), want an empty non-nil slice", f.locked)
+	}
+
+	if err := f.FlattenFields([]string{"name"}); err != nil {
+		t.Fatalf("FlattenFields(name) error = %v", err)
+	}
+	if len(f.locked) != 1 || f.locked[0] != "name" {
+		t.Errorf("locked = %#v, want [name]", f.locked)
+	}
+
+	if err := f.FlattenFields([]string{"missing"}); err == nil {
+		t.Error("FlattenFields(missing) succeeded")
+	}
+
+	if err := f.FlattenFields(nil); err != nil {
+		t.Fatalf("FlattenFields(nil) error = %v", err)
+	}
+	if f.locked != nil {
+		t.Errorf("locked = %#v after FlattenFields(nil), want nil", f.locked)
+	}
+}
@@ -2,6 +2,7 @@ package pdfprocessor
 
 import (
 	"context"
+	"io"
 
 	"github.com/josephmowjew/go-form-processor/types"
 )
@@ -20,4 +21,41 @@ type FormProcessor interface {
 	Upload(ctx context.Context, config types.UploadConfig) (*types.UploadResponse, error)
 	// PrintFields displays all fields and their properties
 	PrintFields()
+	// Summary returns field statistics for the form
+	Summary() FormSummary
+	// GeneratePDF renders the filled form to PDF in memory for later output
+	GeneratePDF() error
+	// Save writes the filled form as a PDF to the output path
+	Save(outputPath string) error
+	// WriteTo writes the filled form as a PDF to w
+	WriteTo(w io.Writer) (int64, error)
+	// ExportValues writes the set field values to w in the given format
+	ExportValues(format string, w io.Writer) error
+}
+
+// FormSummary holds field statistics for a form
+type FormSummary struct {
+	Total    int               // Total number of fields
+	ByType   map[FieldType]int // Number of fields per field type
+	Required int               // Number of required fields, including conditionally required ones
+	Set      int               // Number of fields with a value
+}
+
+// summarize builds a FormSummary from a set of fields, counting the fields for
+// which required reports true as required
+func summarize(fields map[string]Field, required func(Field) bool) FormSummary {
+	summary := FormSummary{
+		Total:  len(fields),
+		ByType: make(map[FieldType]int),
+	}
+	for _, field := range fields {
+		summary.ByType[field.Type]++
+		if required(field) {
+			summary.Required++
+		}
+		if field.Value != nil {
+			summary.Set++
+		}
+	}
+	return summary
 }
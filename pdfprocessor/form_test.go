@@ -0,0 +1,53 @@
+package pdfprocessor
+
+import "testing"
+
+func TestSummary(t *testing.T) {
+	fields := func() map[string]Field {
+		return map[string]Field{
+			"name":     {Name: "name", Type: Text, Required: true, Value: "Ada"},
+			"married":  {Name: "married", Type: Boolean, Value: true},
+			"spouse":   {Name: "spouse", Type: Text},
+			"country":  {Name: "country", Type: Choice, Options: []string{"MW", "ZA"}},
+			"comments": {Name: "comments", Type: Text},
+		}
+	}
+	spouseIfMarried := func(f *PDFForm) bool {
+		married, _ := f.fields["married"].Value.(bool)
+		return married
+	}
+
+	tests := []struct {
+		name         string
+		married      bool
+		conditional  bool
+		wantRequired int
+	}{
+		{name: "static required only", married: true, wantRequired: 1},
+		{name: "condition met", married: true, conditional: true, wantRequired: 2},
+		{name: "condition not met", married: false, conditional: true, wantRequired: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &PDFForm{fields: fields()}
+			married := f.fields["married"]
+			married.Value = tt.married
+			f.fields["married"] = married
+			if tt.conditional {
+				f.SetConditionalRequired("spouse", spouseIfMarried)
+			}
+
+			got := f.Summary()
+			if got.Total != 5 || got.Set != 2 {
+				t.Errorf("Total, Set = %d, %d; want 5, 2", got.Total, got.Set)
+			}
+			if got.ByType[Text] != 3 || got.ByType[Boolean] != 1 || got.ByType[Choice] != 1 {
+				t.Errorf("ByType = %v, want 3 text, 1 boolean, 1 choice", got.ByType)
+			}
+			if got.Required != tt.wantRequired {
+				t.Errorf("Required = %d, want %d", got.Required, tt.wantRequired)
+			}
+		})
+	}
+}
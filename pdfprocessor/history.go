@@ -0,0 +1,35 @@
+package pdfprocessor
+
+import "time"
+
+// HistoryEntry records a value set on a field.
+type HistoryEntry struct {
+	Timestamp time.Time   // When the value was set
+	Value     interface{} // Value stored in the field
+}
+
+// WithHistory records every value set on a field of a PDF form, for audit trails.
+// Use FieldHistory to read a field's history.
+func WithHistory() Option {
+	return func(o *Options) {
+		o.History = true
+	}
+}
+
+// recordHistory appends a value set on a field to its history.
+func (f *PDFForm) recordHistory(name string, value interface{}) {
+	if f.history == nil {
+		f.history = make(map[string][]HistoryEntry)
+	}
+	f.history[name] = append(f.history[name], HistoryEntry{Timestamp: time.Now(), Value: value})
+}
+
+// FieldHistory returns the values set on a field, oldest first. It returns nil
+// unless the form was created with WithHistory.
+func (f *PDFForm) FieldHistory(name string) []HistoryEntry {
+	entries := f.history[name]
+	if entries == nil {
+		return nil
+	}
+	return append([]HistoryEntry(nil), entries...)
+}
@@ -0,0 +1,91 @@
+package pdfprocessor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFieldHistory(t *testing.T) {
+	type set struct {
+		field string
+		value interface{}
+	}
+	tests := []struct {
+		name    string
+		opts    []Option
+		sets    []set
+		field   string
+		want    []interface{} // Values recorded for field, nil for no history
+		wantNil bool
+	}{
+		{
+			name:  "values in order",
+			opts:  []Option{WithHistory()},
+			sets:  []set{{"name", "Ada"}, {"amount", "1"}, {"name", "Grace"}, {"name", "Grace"}},
+			field: "name",
+			want:  []interface{}{"Ada", "Grace", "Grace"},
+		},
+		{
+			name:  "converted value recorded",
+			opts:  []Option{WithHistory(), WithAutoConvert()},
+			sets:  []set{{"agree", "yes"}, {"amount", 42}},
+			field: "amount",
+			want:  []interface{}{"42"},
+		},
+		{
+			name:  "rejected value not recorded",
+			opts:  []Option{WithHistory()},
+			sets:  []set{{"country", "US"}, {"country", "ZA"}, {"country", 7}},
+			field: "country",
+			want:  []interface{}{"ZA"},
+		},
+		{
+			name:    "field never set",
+			opts:    []Option{WithHistory()},
+			sets:    []set{{"name", "Ada"}},
+			field:   "notes",
+			wantNil: true,
+		},
+		{
+			name:    "history disabled",
+			sets:    []set{{"name", "Ada"}},
+			field:   "name",
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestForm(t, testDump, tt.opts...)
+			start := time.Now()
+			for _, s := range tt.sets {
+				f.SetField(s.field, s.value) // Rejected values are part of the test
+			}
+
+			entries := f.FieldHistory(tt.field)
+			if tt.wantNil {
+				if entries != nil {
+					t.Fatalf("FieldHistory(%s) = %v, want nil", tt.field, entries)
+				}
+				return
+			}
+			var values []interface{}
+			for i, entry := range entries {
+				values = append(values, entry.Value)
+				if entry.Timestamp.Before(start) || (i > 0 && entry.Timestamp.Before(entries[i-1].Timestamp)) {
+					t.Errorf("entry %d timestamp %v is out of order", i, entry.Timestamp)
+				}
+			}
+			if !reflect.DeepEqual(values, tt.want) {
+				t.Errorf("FieldHistory(%s) values = %v, want %v", tt.field, values, tt.want)
+			}
+
+			// The returned slice is a copy
+			entries[0].Value = "changed"
+			if f.FieldHistory(tt.field)[0].Value == "changed" {
+				t.Error("FieldHistory returned the form's own slice")
+			}
+		})
+	}
+}
@@ -3,11 +3,15 @@ package pdfprocessor
 import (
 	"context"
 	"fmt"
+	"html"
 	"io"
-	"log"
-	"net/http"
+	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -16,36 +20,144 @@ import (
 	"github.com/josephmowjew/go-form-processor/types"
 )
 
-// HTMLForm represents an HTML form with its fields and configuration
+// defaultHTMLStyles is the stylesheet added to HTML forms for PDF generation. Its
+// selectors use :where so they have no specificity and lose to any rule in the
+// form's own styles, and inputs with a class or inline style are left untouched.
+const defaultHTMLStyles = `
+		<style>
+			:where(body) {
+				font-family: Arial, sans-serif;
+				line-height: 1.6;
+				margin: 20px;
+			}
+			:where(input, select, textarea):where(:not([class]):not([style])) {
+				border: 1px solid #ccc;
+				padding: 5px;
+				margin: 5px 0;
+			}
+			:where(input[type="checkbox"], input[type="radio"]):where(:not([class]):not([style])) {
+				margin-right: 5px;
+			}
+			:where(label) {
+				display: inline-block;
+				margin-right: 10px;
+			}
+		</style>
+	`
+
+// WithCSS adds a stylesheet to HTML forms before PDF generation. It is added after
+// the default styles, so its rules override them
+func WithCSS(css string) Option {
+	return func(o *Options) {
+		o.CSS = append(o.CSS, css)
+	}
+}
+
+// WithStylesheetURL links an external stylesheet into HTML forms before PDF generation
+func WithStylesheetURL(url string) Option {
+	return func(o *Options) {
+		o.StylesheetURLs = append(o.StylesheetURLs, url)
+	}
+}
+
+// WithoutDefaultStyles stops the built-in styles being added to HTML forms before PDF generation
+func WithoutDefaultStyles() Option {
+	return func(o *Options) {
+		o.DisableDefaultStyles = true
+	}
+}
+
+// WithFonts adds font files (TTF, OTF, WOFF or WOFF2) to HTML forms before PDF
+// generation, e.g. for Arabic or CJK text the installed fonts don't cover. Each
+// font is declared with @font-face under its file name without extension, e.g.
+// "NotoSansSC-Regular", and put ahead of Arial in the default font of the body
+// and form controls. Forms that set their own font-family should list these
+// names in it. Fonts are linked by file URL, so a fallback renderer must be able
+// to read local files
+func WithFonts(paths []string) Option {
+	return func(o *Options) {
+		o.Fonts = append(o.Fonts, paths...)
+	}
+}
+
+// WithTextDirection sets the dir attribute of HTML forms before PDF generation,
+// "rtl" for right-to-left scripts such as Arabic or Hebrew, or "ltr"
+func WithTextDirection(dir string) Option {
+	return func(o *Options) {
+		o.TextDirection = strings.ToLower(dir)
+	}
+}
+
+// fontStyles returns a style element declaring the fonts added with WithFonts and
+// using them by default, or an empty string if there are none
+func (o Options) fontStyles() string {
+	if len(o.Fonts) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	var families []string
+	for _, path := range o.Fonts {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			o.logEvent(slog.LevelError, "Skipping font", "path", path, "error", err)
+			continue
+		}
+		abs = filepath.ToSlash(abs)
+		if !strings.HasPrefix(abs, "/") {
+			abs = "/" + abs // Windows drive letter
+		}
+		fontURL := (&url.URL{Scheme: "file", Path: abs}).String()
+		family := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		families = append(families, strconv.Quote(family))
+		fmt.Fprintf(&b, "@font-face { font-family: %s; src: url(%s); }\n", strconv.Quote(family), strconv.Quote(fontURL))
+	}
+	if len(families) == 0 {
+		return ""
+	}
+	fmt.Fprintf(&b, ":where(body, input, select, textarea) { font-family: %s, Arial, sans-serif; }\n", strings.Join(families, ", "))
+	return "<style>" + strings.ReplaceAll(b.String(), "</", `<\/`) + "</style>"
+}
+
+// HTMLRenderer converts filled HTML to PDF
+type HTMLRenderer func(ctx context.Context, html string) ([]byte, error)
+
+// WithFallbackRenderer sets the renderer GeneratePDF uses when Chrome is not installed,
+// e.g. one calling wkhtmltopdf or a remote conversion service
+func WithFallbackRenderer(renderer HTMLRenderer) Option {
+	return func(o *Options) {
+		o.FallbackRenderer = renderer
+	}
+}
+
+// HTMLForm represents an HTML form with its fields and configuration.
+// Its methods may be called on the same form from different goroutines
 type HTMLForm struct {
-	fields   map[string]Field
 	inputURL string
-	rawHTML  string
+	rawHTML  string // Never modified after the form is created
 	options  Options
-	pdfData  []byte // Add this field to store the generated PDF
+
+	mu      sync.Mutex       // Guards fields and pdfData once the form is created
+	fields  map[string]Field // Fields keyed by name; read through GetFields outside mu
+	pdfData []byte           // PDF rendered by GeneratePDF, replaced on each render
 }
 
 // NewHTMLFormFromURL creates a new HTMLForm instance from a URL
 func NewHTMLFormFromURL(url string, opts ...Option) (*HTMLForm, error) {
+	options := newOptions(opts)
+
 	// Fetch the HTML content
-	resp, err := http.Get(url)
+	download, err := options.download(context.Background(), url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch HTML: %w", err)
 	}
-	defer resp.Body.Close()
+	defer download.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(download)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read HTML body: %w", err)
 	}
 
-	options := Options{
-		Logger: log.Default(),
-	}
-	for _, opt := range opts {
-		opt(&options)
-	}
-
 	form := &HTMLForm{
 		inputURL: url,
 		rawHTML:  string(body),
@@ -57,18 +169,16 @@ func NewHTMLFormFromURL(url string, opts ...Option) (*HTMLForm, error) {
 		return nil, fmt.Errorf("failed to load form fields: %w", err)
 	}
 
+	if err := applyDefaults(form, options.Defaults); err != nil {
+		return nil, err
+	}
+
 	return form, nil
 }
 
 // loadFields reads field information from the HTML document
 func (f *HTMLForm) loadFields() error {
-	resp, err := http.Get(f.inputURL)
-	if err != nil {
-		return fmt.Errorf("failed to fetch HTML: %w", err)
-	}
-	defer resp.Body.Close()
-
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(f.rawHTML))
 	if err != nil {
 		return fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -82,6 +192,7 @@ func (f *HTMLForm) loadFields() error {
 
 		field := Field{
 			Name:     name,
+			Tooltip:  s.AttrOr("title", ""),
 			Required: s.AttrOr("required", "") != "",
 			Options:  []string{},
 		}
@@ -94,6 +205,7 @@ func (f *HTMLForm) loadFields() error {
 			s.Find("option").Each(func(i int, opt *goquery.Selection) {
 				if value, exists := opt.Attr("value"); exists {
 					field.Options = append(field.Options, value)
+					field.OptionLabels = append(field.OptionLabels, strings.TrimSpace(opt.Text()))
 				}
 			})
 		case s.Is("input"):
@@ -106,15 +218,27 @@ func (f *HTMLForm) loadFields() error {
 		case s.Is("textarea"):
 			field.Type = Text
 		}
+		if field.Type == Text {
+			field.MaxLength, _ = strconv.Atoi(s.AttrOr("maxlength", ""))
+		}
 
 		f.fields[name] = field
 	})
 
+	if f.options.TemplateExecution {
+		if err := f.loadTemplateFields(); err != nil {
+			return err
+		}
+	}
+
+	f.options.overrideFields(f.fields)
 	return nil
 }
 
 // GetFields returns all form fields
 func (f *HTMLForm) GetFields() map[string]Field {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	fields := make(map[string]Field, len(f.fields))
 	for k, v := range f.fields {
 		fields[k] = v
@@ -124,11 +248,26 @@ func (f *HTMLForm) GetFields() map[string]Field {
 
 // SetField sets a value for a specific form field
 func (f *HTMLForm) SetField(name string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	field, exists := f.fields[name]
 	if !exists {
+		if f.options.IgnoreUnknownFields {
+			f.options.logEvent(slog.LevelInfo, "Skipping unknown field", "field", name)
+			return nil
+		}
 		return fmt.Errorf("field %s not found in form", name)
 	}
 
+	if f.options.AutoConvert || f.options.ValidationMode == Lenient {
+		converted, err := convertFieldValue(field, value, f.options.LooseOptionMatching)
+		if err != nil {
+			return err
+		}
+		value = converted
+	}
+
 	// Type validation
 	switch field.Type {
 	case Text:
@@ -141,9 +280,11 @@ func (f *HTMLForm) SetField(name string, value interface{}) error {
 		}
 	case Choice:
 		if strVal, ok := value.(string); ok {
-			if !isValidOption(strVal, field.Options) {
+			option, found := matchOption(strVal, field.Options, f.options.LooseOptionMatching)
+			if !found {
 				return fmt.Errorf("invalid option for field %s: %s", name, strVal)
 			}
+			value = option
 		} else {
 			return fmt.Errorf("field %s requires string value from options", name)
 		}
@@ -151,6 +292,8 @@ func (f *HTMLForm) SetField(name string, value interface{}) error {
 
 	field.Value = value
 	f.fields[name] = field
+	f.pdfData = nil
+	f.options.logEvent(slog.LevelDebug, "Field set", "field", name)
 
 	if f.options.ValidateOnSet {
 		return f.validateField(field)
@@ -159,25 +302,27 @@ func (f *HTMLForm) SetField(name string, value interface{}) error {
 }
 
 // SetFields sets multiple field values
+// If some fields cannot be set, the returned error is an *ErrSetFields listing them.
 func (f *HTMLForm) SetFields(fields map[string]interface{}) error {
-	var errors []string
+	setErr := &ErrSetFields{}
+	known := f.GetFields()
 
 	for name, value := range fields {
+		if _, exists := known[name]; !exists && !f.options.IgnoreUnknownFields {
+			setErr.UnmatchedFields = append(setErr.UnmatchedFields, name)
+			continue
+		}
 		if err := f.SetField(name, value); err != nil {
-			errors = append(errors, fmt.Sprintf("field '%s': %v", name, err))
+			setErr.add(name, err)
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to set some fields: %s", strings.Join(errors, "; "))
-	}
-
-	return nil
+	return setErr.orNil()
 }
 
 // Validate checks if all required fields have values
 func (f *HTMLForm) Validate() error {
-	for _, field := range f.fields {
+	for _, field := range f.GetFields() {
 		if err := f.validateField(field); err != nil {
 			return err
 		}
@@ -191,12 +336,10 @@ func (f *HTMLForm) Upload(ctx context.Context, config types.UploadConfig) (*type
 		return nil, fmt.Errorf("uploader service not configured")
 	}
 
-	// Use PDF data if available, otherwise use HTML
-	var data []byte
-	if f.pdfData != nil {
-		data = f.pdfData
-	} else {
-		data = []byte(f.generateFilledHTML())
+	// Only rendered PDFs may be uploaded; use UploadHTML for the HTML itself
+	pdfData := f.renderedPDF()
+	if pdfData == nil {
+		return nil, ErrPDFNotGenerated
 	}
 
 	// Ensure filename has .pdf extension
@@ -204,15 +347,87 @@ func (f *HTMLForm) Upload(ctx context.Context, config types.UploadConfig) (*type
 		config.FileName = config.FileName + ".pdf"
 	}
 
-	// Upload the filled form
+	return f.upload(ctx, pdfData, config)
+}
+
+// UploadHTML uploads the filled HTML form without converting it to PDF
+func (f *HTMLForm) UploadHTML(ctx context.Context, config types.UploadConfig) (*types.UploadResponse, error) {
+	if f.options.Uploader == nil {
+		return nil, fmt.Errorf("uploader service not configured")
+	}
+
+	// Ensure filename has .html extension
+	if !strings.HasSuffix(config.FileName, ".html") {
+		config.FileName = strings.TrimSuffix(config.FileName, ".pdf") + ".html"
+	}
+
+	return f.upload(ctx, []byte(f.generateFilledHTML()), config)
+}
+
+// upload sends data through the configured uploader and logs the outcome
+func (f *HTMLForm) upload(ctx context.Context, data []byte, config types.UploadConfig) (*types.UploadResponse, error) {
+	start := time.Now()
 	response, err := f.options.Uploader.Upload(ctx, data, config)
 	if err != nil {
+		f.options.logEvent(slog.LevelError, "Upload failed", "file", config.FileName, "status", "failed", "duration", time.Since(start), "error", err)
 		return nil, fmt.Errorf("failed to upload form: %w", err)
 	}
+	f.options.logEvent(slog.LevelInfo, "Upload succeeded", "file", config.FileName, "status", "success", "duration", time.Since(start), "size", len(data))
 
 	return response, nil
 }
 
+// Summary returns field counts for the HTML form
+func (f *HTMLForm) Summary() FormSummary {
+	return summarize(f.GetFields(), func(field Field) bool { return field.Required })
+}
+
+// Save generates the PDF for the filled HTML form and writes it to the output path,
+// replacing any existing file atomically
+func (f *HTMLForm) Save(outputPath string) error {
+	if err := ensureOutputDir(outputPath); err != nil {
+		return err
+	}
+	if err := f.GeneratePDF(); err != nil {
+		return err
+	}
+	pdfData := f.renderedPDF()
+	return atomicWrite(outputPath, func(tempPath string) error {
+		if err := os.WriteFile(tempPath, pdfData, 0644); err != nil {
+			return fmt.Errorf("failed to write PDF: %w", err)
+		}
+		return nil
+	})
+}
+
+// WriteTo writes the generated PDF to w, generating it first if needed
+func (f *HTMLForm) WriteTo(w io.Writer) (int64, error) {
+	pdfData := f.renderedPDF()
+	if pdfData == nil {
+		if err := f.GeneratePDF(); err != nil {
+			return 0, err
+		}
+		pdfData = f.renderedPDF()
+	}
+	n, err := w.Write(pdfData)
+	return int64(n), err
+}
+
+// renderedPDF returns the PDF stored by the last GeneratePDF, or nil. The slice
+// is replaced rather than modified by later renders, so callers may keep it
+func (f *HTMLForm) renderedPDF() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pdfData
+}
+
+// setRenderedPDF stores a PDF rendered by GeneratePDF
+func (f *HTMLForm) setRenderedPDF(pdfData []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pdfData = pdfData
+}
+
 // PrintFields displays all fields and their properties
 func (f *HTMLForm) PrintFields() {
 	if f.options.Logger == nil {
@@ -222,7 +437,7 @@ func (f *HTMLForm) PrintFields() {
 	f.options.Logger.Println("HTML Form Fields:")
 	f.options.Logger.Println("================")
 
-	for name, field := range f.fields {
+	for name, field := range f.GetFields() {
 		fieldType := "Text"
 		switch field.Type {
 		case Boolean:
@@ -246,13 +461,22 @@ func (f *HTMLForm) PrintFields() {
 
 // generateFilledHTML creates a filled version of the HTML form
 func (f *HTMLForm) generateFilledHTML() string {
+	fields := f.GetFields()
+	source := f.rawHTML
+	if f.options.TemplateExecution {
+		executed, err := f.executeTemplate(fields)
+		if err != nil {
+			f.options.logEvent(slog.LevelError, "Error executing HTML template", "error", err)
+			return f.rawHTML
+		}
+		source = executed
+	}
+
 	// Parse the HTML document
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(f.rawHTML))
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(source))
 	if err != nil {
-		if f.options.Logger != nil {
-			f.options.Logger.Printf("Error parsing HTML: %v", err)
-		}
-		return f.rawHTML
+		f.options.logEvent(slog.LevelError, "Error parsing HTML", "error", err)
+		return source
 	}
 
 	// Fill in form fields
@@ -262,7 +486,7 @@ func (f *HTMLForm) generateFilledHTML() string {
 			return
 		}
 
-		field, exists := f.fields[name]
+		field, exists := fields[name]
 		if !exists || field.Value == nil {
 			return
 		}
@@ -290,44 +514,37 @@ func (f *HTMLForm) generateFilledHTML() string {
 		}
 	})
 
-	// Add necessary styling for PDF generation
-	doc.Find("head").AppendHtml(`
-		<style>
-			body {
-				font-family: Arial, sans-serif;
-				line-height: 1.6;
-				margin: 20px;
-			}
-			input, select, textarea {
-				border: 1px solid #ccc;
-				padding: 5px;
-				margin: 5px 0;
-			}
-			input[type="checkbox"], input[type="radio"] {
-				margin-right: 5px;
-			}
-			label {
-				display: inline-block;
-				margin-right: 10px;
-			}
-		</style>
-	`)
+	if f.options.TextDirection != "" {
+		doc.Find("html").SetAttr("dir", f.options.TextDirection)
+	}
+
+	// Add necessary styling for PDF generation, followed by the caller's styles so they take precedence
+	head := doc.Find("head")
+	if !f.options.DisableDefaultStyles {
+		head.AppendHtml(defaultHTMLStyles)
+	}
+	if fonts := f.options.fontStyles(); fonts != "" {
+		head.AppendHtml(fonts)
+	}
+	for _, url := range f.options.StylesheetURLs {
+		head.AppendHtml(fmt.Sprintf(`<link rel="stylesheet" href="%s">`, html.EscapeString(url)))
+	}
+	for _, css := range f.options.CSS {
+		// Keep the stylesheet from closing its own style element
+		head.AppendHtml("<style>" + strings.ReplaceAll(css, "</", `<\/`) + "</style>")
+	}
 
 	// Generate the HTML string
-	html, err := doc.Html()
+	filled, err := doc.Html()
 	if err != nil {
-		if f.options.Logger != nil {
-			f.options.Logger.Printf("Error generating HTML: %v", err)
-		}
-		return f.rawHTML
+		f.options.logEvent(slog.LevelError, "Error generating HTML", "error", err)
+		return source
 	}
 
 	// Log the generated HTML for debugging
-	if f.options.Logger != nil {
-		f.options.Logger.Printf("Generated HTML:\n%s", html)
-	}
+	f.options.logEvent(slog.LevelInfo, "Generated HTML", "html", filled)
 
-	return html
+	return filled
 }
 
 func (f *HTMLForm) validateField(field Field) error {
@@ -339,8 +556,14 @@ func (f *HTMLForm) validateField(field Field) error {
 
 // GeneratePDF converts the filled HTML form to PDF format
 func (f *HTMLForm) GeneratePDF() error {
+	chromePath, found := findChrome()
+	if !found {
+		return f.generateFallbackPDF()
+	}
+
 	// Create a new Chrome instance
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.ExecPath(chromePath),
 		chromedp.Flag("headless", true),
 		chromedp.Flag("disable-gpu", true),
 		chromedp.Flag("no-sandbox", true),
@@ -366,7 +589,7 @@ func (f *HTMLForm) GeneratePDF() error {
 		return fmt.Errorf("failed to create temporary HTML file: %w", err)
 	}
 	tmpHTMLPath := tmpHTML.Name()
-	defer os.Remove(tmpHTMLPath)
+	defer f.options.removeTemp(tmpHTMLPath)
 
 	// Write the filled HTML to the temporary file
 	if err := os.WriteFile(tmpHTMLPath, []byte(filledHTML), 0644); err != nil {
@@ -401,11 +624,30 @@ func (f *HTMLForm) GeneratePDF() error {
 	}
 
 	// Store the PDF data in memory for later use by the Upload method
-	f.pdfData = pdfData
+	f.setRenderedPDF(pdfData)
+
+	f.options.logEvent(slog.LevelInfo, "PDF generated successfully", "size", len(pdfData))
 
-	if f.options.Logger != nil {
-		f.options.Logger.Printf("PDF generated successfully, size: %d bytes", len(pdfData))
+	return nil
+}
+
+// generateFallbackPDF converts the filled HTML with the fallback renderer, used when Chrome is missing
+func (f *HTMLForm) generateFallbackPDF() error {
+	if f.options.FallbackRenderer == nil {
+		return ErrChromeNotFound
+	}
+	f.options.logEvent(slog.LevelWarn, "Chrome not found, using fallback renderer")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pdfData, err := f.options.FallbackRenderer(ctx, f.generateFilledHTML())
+	if err != nil {
+		return fmt.Errorf("failed to generate PDF: %w", err)
 	}
 
+	f.setRenderedPDF(pdfData)
+	f.options.logEvent(slog.LevelInfo, "PDF generated successfully", "size", len(pdfData), "renderer", "fallback")
+
 	return nil
 }
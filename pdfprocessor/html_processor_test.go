@@ -0,0 +1,176 @@
+package pdfprocessor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/josephmowjew/go-form-processor/types"
+)
+
+// stringDownloader serves a fixed document for every URL.
+type stringDownloader string
+
+func (d stringDownloader) Download(ctx context.Context, url string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(d))), nil
+}
+
+// recordingUploader records the data of each upload.
+type recordingUploader struct {
+	mu      sync.Mutex
+	uploads [][]byte
+}
+
+func (u *recordingUploader) Upload(ctx context.Context, data []byte, config types.UploadConfig) (*types.UploadResponse, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.uploads = append(u.uploads, data)
+	return &types.UploadResponse{FileName: config.FileName}, nil
+}
+
+// withoutChrome hides any installed Chrome so GeneratePDF uses the fallback renderer.
+func withoutChrome(t *testing.T) {
+	t.Helper()
+	saved := chromeExecutables
+	chromeExecutables = map[string][]string{}
+	t.Cleanup(func() { chromeExecutables = saved })
+}
+
+// newTestHTMLForm loads a one-field HTML form whose fallback renderer returns a
+// fake PDF naming the value of the field.
+func newTestHTMLForm(t *testing.T, uploader *recordingUploader) *HTMLForm {
+	t.Helper()
+	withoutChrome(t)
+
+	renderer := func(ctx context.Context, html string) ([]byte, error) {
+		value := "empty"
+		if strings.Contains(html, `value="Ada"`) {
+			value = "Ada"
+		}
+		return []byte("%PDF-1.4 " + value), nil
+	}
+
+	form, err := NewHTMLFormFromURL("https://example.com/form.html",
+		WithDownloader(stringDownloader(`<html><head></head><body><input type="text" name="name"></body></html>`)),
+		WithFallbackRenderer(renderer),
+		WithUploader(uploader),
+		WithLogger(nil),
+	)
+	if err != nil {
+		t.Fatalf("NewHTMLFormFromURL returned error: %v", err)
+	}
+	return form
+}
+
+func TestHTMLFormConcurrentGenerateAndUpload(t *testing.T) {
+	uploader := &recordingUploader{}
+	form := newTestHTMLForm(t, uploader)
+	if err := form.GeneratePDF(); err != nil {
+		t.Fatalf("GeneratePDF returned error: %v", err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs <- form.GeneratePDF()
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := form.Upload(context.Background(), types.UploadConfig{
+				FileName:       "form.pdf",
+				OrganizationID: "org",
+				BranchID:       "branch",
+				CreatedBy:      "test",
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent call returned error: %v", err)
+		}
+	}
+	if len(uploader.uploads) != workers {
+		t.Errorf("got %d uploads, want %d", len(uploader.uploads), workers)
+	}
+}
+
+func TestHTMLFormConcurrentSetFieldAndGenerate(t *testing.T) {
+	uploader := &recordingUploader{}
+	form := newTestHTMLForm(t, uploader)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 4*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			errs <- form.SetField("name", "Ada")
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- form.GeneratePDF()
+		}()
+		go func() {
+			defer wg.Done()
+			// Upload races with SetField clearing the rendered PDF
+			_, err := form.Upload(context.Background(), types.UploadConfig{FileName: "form.pdf"})
+			if errors.Is(err, ErrPDFNotGenerated) {
+				err = nil
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			form.Summary()
+			errs <- form.ExportValues(ExportJSON, io.Discard)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent call returned error: %v", err)
+		}
+	}
+	if got := form.GetFields()["name"].Value; got != "Ada" {
+		t.Errorf("name = %v, want Ada", got)
+	}
+}
+
+func TestHTMLFormSetFieldClearsRenderedPDF(t *testing.T) {
+	uploader := &recordingUploader{}
+	form := newTestHTMLForm(t, uploader)
+	if err := form.GeneratePDF(); err != nil {
+		t.Fatalf("GeneratePDF returned error: %v", err)
+	}
+
+	if err := form.SetField("name", "Ada"); err != nil {
+		t.Fatalf("SetField returned error: %v", err)
+	}
+	_, err := form.Upload(context.Background(), types.UploadConfig{FileName: "form.pdf"})
+	if !errors.Is(err, ErrPDFNotGenerated) {
+		t.Errorf("Upload after SetField returned %v, want ErrPDFNotGenerated", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := form.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo returned error: %v", err)
+	}
+	if got := buf.String(); got != "%PDF-1.4 Ada" {
+		t.Errorf("WriteTo wrote %q, want the PDF rendered after SetField", got)
+	}
+}
@@ -0,0 +1,110 @@
+package pdfprocessor
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"text/template/parse"
+)
+
+// WithTemplateExecution executes HTML forms as Go templates against the field values
+// before their inputs are filled, so placeholders such as {{.firstName}} are
+// populated too. Placeholder names become text fields of the form. Values are
+// escaped for their HTML context as with html/template.
+func WithTemplateExecution() Option {
+	return func(o *Options) {
+		o.TemplateExecution = true
+	}
+}
+
+// parseTemplate parses the raw HTML of the form as a template
+func (f *HTMLForm) parseTemplate() (*template.Template, error) {
+	tmpl, err := template.New("form").Option("missingkey=zero").Parse(f.rawHTML)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// loadTemplateFields adds a text field for each placeholder in the template that
+// is not already an input of the form
+func (f *HTMLForm) loadTemplateFields() error {
+	tmpl, err := f.parseTemplate()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range templateFieldNames(tmpl.Tree.Root) {
+		if _, exists := f.fields[name]; !exists {
+			f.fields[name] = Field{Name: name, Type: Text, Options: []string{}}
+		}
+	}
+	return nil
+}
+
+// executeTemplate renders the raw HTML of the form with the values of fields
+func (f *HTMLForm) executeTemplate(fields map[string]Field) (string, error) {
+	tmpl, err := f.parseTemplate()
+	if err != nil {
+		return "", err
+	}
+
+	data := make(map[string]interface{}, len(fields))
+	for name, field := range fields {
+		data[name] = field.Value
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to execute HTML template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// templateFieldNames returns the top-level field names referenced by a template,
+// such as firstName in {{.firstName}} or {{if .married}}
+func templateFieldNames(node parse.Node) []string {
+	var names []string
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			// Fields inside with and range blocks are relative to another value
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			names = append(names, n.Ident[0])
+		}
+	}
+	walk(node)
+	return names
+}
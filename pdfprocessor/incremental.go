@@ -0,0 +1,138 @@
+package pdfprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WithIncrementalSave fills forms by appending an incremental update to the
+// template instead of rewriting it, so revisions covered by an existing digital
+// signature are preserved and the signature stays valid. pdftk always rewrites
+// the whole document, so the update is written with MuPDF's mutool, which must
+// be installed. Fields are never flattened in this mode, and page selection,
+// rotation, watermarks, attachments, annotation removal, overflow addenda,
+// document info and PDF/A conversion, which rewrite the document, make saving
+// fail.
+func WithIncrementalSave() Option {
+	return func(o *Options) {
+		o.IncrementalSave = true
+	}
+}
+
+// incrementalFillScript is run by mutool to set the field values read from a
+// JSON file and append them to the PDF as an incremental update. Signature
+// fields are left untouched, and a radio group is toggled only once.
+const incrementalFillScript = `var doc = Document.openDocument(scriptArgs[0]);
+var values = JSON.parse(read(scriptArgs[1]));
+var toggled = {};
+for (var i = 0; i < doc.countPages(); i++) {
+	var widgets = doc.loadPage(i).getWidgets();
+	for (var j = 0; j < widgets.length; j++) {
+		var widget = widgets[j];
+		var name = widget.getName();
+		if (!values.hasOwnProperty(name)) {
+			continue;
+		}
+		var value = values[name];
+		switch (widget.getFieldType()) {
+		case "text":
+			widget.setTextValue(value);
+			break;
+		case "combobox":
+		case "listbox":
+			widget.setChoiceValue(value);
+			break;
+		case "checkbox":
+		case "radiobutton":
+			if (toggled[name] || (widget.getValue() !== "Off") === (value !== "Off")) {
+				continue;
+			}
+			widget.toggle();
+			toggled[name] = true;
+			break;
+		default:
+			continue;
+		}
+		widget.update();
+	}
+}
+doc.save(scriptArgs[0], "incremental");
+`
+
+// fillIncremental copies the template to out and appends the field values to
+// it as an incremental update.
+func (f *PDFForm) fillIncremental(ctx context.Context, out string) error {
+	if err := copyFile(f.inputPath, out); err != nil {
+		return err
+	}
+
+	script, err := os.CreateTemp("", "form-fill-*.js")
+	if err != nil {
+		return fmt.Errorf("failed to create fill script: %w", err)
+	}
+	defer f.options.removeTemp(script.Name())
+	_, err = script.WriteString(incrementalFillScript)
+	script.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write fill script: %w", err)
+	}
+
+	values, err := f.formData()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode field values: %w", err)
+	}
+	valuesFile, err := os.CreateTemp("", "form-data-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create field values file: %w", err)
+	}
+	defer f.options.removeTemp(valuesFile.Name())
+	_, err = valuesFile.Write(data)
+	valuesFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write field values file: %w", err)
+	}
+
+	if _, err := f.options.runCommand(ctx, "mutool", "run", script.Name(), out, valuesFile.Name()); err != nil {
+		return fmt.Errorf("failed to save PDF incrementally: %w", err)
+	}
+	return nil
+}
+
+// checkIncremental returns ErrIncrementalRewrite if the form is saved
+// incrementally but also configured to rewrite the document.
+func (f *PDFForm) checkIncremental() error {
+	if !f.options.IncrementalSave {
+		return nil
+	}
+	if len(f.pages) > 0 || len(f.rotations) > 0 || f.watermark != nil || len(f.attached) > 0 || len(f.removed) > 0 ||
+		f.options.OverflowAddendum || len(f.options.DocumentInfo) > 0 || f.options.ClearDocumentInfo || f.options.PDFALevel != "" {
+		return ErrIncrementalRewrite
+	}
+	return nil
+}
+
+// copyFile copies the file at src to dst, replacing dst if it exists.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,50 @@
+package pdfprocessor
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LoadForms loads the templates at paths with NewForm using up to concurrency
+// parallel workers, each running its own pdftk process. Forms are returned keyed
+// by the path they were loaded from, and paths that fail to load are left out
+// and reported with an error naming the path, in the order of paths. Every form
+// gets its own copy of the options built from opts, so options holding state,
+// such as a logger or HTTP client, must be safe for concurrent use.
+func LoadForms(paths []string, concurrency int, opts ...Option) (map[string]*PDFForm, []error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	forms := make([]*PDFForm, len(paths))
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				forms[i], errs[i] = NewForm(paths[i], opts...)
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	loaded := make(map[string]*PDFForm, len(paths))
+	var failed []error
+	for i, path := range paths {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Errorf("failed to load %s: %w", path, errs[i]))
+			continue
+		}
+		loaded[path] = forms[i]
+	}
+	return loaded, failed
+}
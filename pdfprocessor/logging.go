@@ -0,0 +1,37 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// WithSlog routes the processor's logging through a structured slog logger.
+// Messages carry attributes such as field, status and duration. When set, it
+// takes precedence over the logger configured with WithLogger, except for PrintFields.
+func WithSlog(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Slog = logger
+	}
+}
+
+// logEvent logs a message with key/value attributes. Structured loggers receive the
+// attributes as-is; the standard logger gets them appended as key=value pairs and
+// only receives messages at info level and above.
+func (o Options) logEvent(level slog.Level, msg string, args ...any) {
+	if o.Slog != nil {
+		o.Slog.Log(context.Background(), level, msg, args...)
+		return
+	}
+	if o.Logger == nil || level < slog.LevelInfo {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		sb.WriteString(fmt.Sprintf(" %v=%v", args[i], args[i+1]))
+	}
+	o.Logger.Print(sb.String())
+}
@@ -0,0 +1,76 @@
+package pdfprocessor
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat describes how a number set on a text field is written to the PDF.
+type NumberFormat struct {
+	Decimals           int    // Number of digits after the decimal separator
+	DecimalSeparator   string // Separator before the decimals, "." if empty
+	ThousandsSeparator string // Separator between groups of three integer digits, none if empty
+	Prefix             string // Text written before the number, such as a currency symbol
+	Suffix             string // Text written after the number, such as " EUR"
+}
+
+// WithNumberFormat formats the numeric value of a text field when the form is
+// filled, e.g. writing "25000" as "25,000.00". Values that are not numbers are
+// written unchanged. It applies to PDF forms only.
+func WithNumberFormat(fieldName string, format NumberFormat) Option {
+	return func(o *Options) {
+		if o.NumberFormats == nil {
+			o.NumberFormats = make(map[string]NumberFormat)
+		}
+		o.NumberFormats[fieldName] = format
+	}
+}
+
+// Format formats a number, rounding it to the configured number of decimals.
+func (n NumberFormat) Format(value float64) string {
+	digits := strconv.FormatFloat(math.Abs(value), 'f', n.Decimals, 64)
+	integer, fraction, _ := strings.Cut(digits, ".")
+
+	if n.ThousandsSeparator != "" {
+		var grouped strings.Builder
+		for i, digit := range integer {
+			if i > 0 && (len(integer)-i)%3 == 0 {
+				grouped.WriteString(n.ThousandsSeparator)
+			}
+			grouped.WriteRune(digit)
+		}
+		integer = grouped.String()
+	}
+
+	var b strings.Builder
+	if value < 0 && strings.Trim(digits, "0.") != "" {
+		b.WriteString("-")
+	}
+	b.WriteString(n.Prefix)
+	b.WriteString(integer)
+	if fraction != "" {
+		separator := n.DecimalSeparator
+		if separator == "" {
+			separator = "."
+		}
+		b.WriteString(separator)
+		b.WriteString(fraction)
+	}
+	b.WriteString(n.Suffix)
+	return b.String()
+}
+
+// formatNumber applies the field's number format to a value written as text,
+// returning the text unchanged if it has no format or is not a number.
+func (o Options) formatNumber(name, text string) string {
+	format, ok := o.NumberFormats[name]
+	if !ok {
+		return text
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return text
+	}
+	return format.Format(value)
+}
@@ -0,0 +1,64 @@
+package pdfprocessor
+
+import "testing"
+
+func TestNumberFormat(t *testing.T) {
+	currency := NumberFormat{Decimals: 2, ThousandsSeparator: ",", Prefix: "$"}
+	european := NumberFormat{Decimals: 2, DecimalSeparator: ",", ThousandsSeparator: ".", Suffix: " EUR"}
+
+	tests := []struct {
+		name   string
+		format NumberFormat
+		value  float64
+		want   string
+	}{
+		{name: "plain integer", format: NumberFormat{}, value: 25000, want: "25000"},
+		{name: "rounded", format: NumberFormat{Decimals: 1}, value: 2.25, want: "2.2"},
+		{name: "grouped", format: currency, value: 25000, want: "$25,000.00"},
+		{name: "grouped millions", format: currency, value: 1234567.891, want: "$1,234,567.89"},
+		{name: "under a thousand", format: currency, value: 999, want: "$999.00"},
+		{name: "negative", format: currency, value: -1500.5, want: "-$1,500.50"},
+		{name: "negative rounding to zero", format: currency, value: -0.001, want: "$0.00"},
+		{name: "european", format: european, value: 1234.5, want: "1.234,50 EUR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.Format(tt.value); got != tt.want {
+				t.Errorf("Format(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithNumberFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		value string
+		want  string
+	}{
+		{name: "number", field: "amount", value: "25000", want: "25,000.00"},
+		{name: "padded number", field: "amount", value: " 12.5 ", want: "12.50"},
+		{name: "not a number", field: "amount", value: "n/a", want: "n/a"},
+		{name: "infinity", field: "amount", value: "Inf", want: "Inf"},
+		{name: "field without format", field: "notes", value: "25000", want: "25000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestForm(t, testDump, WithNumberFormat("amount", NumberFormat{Decimals: 2, ThousandsSeparator: ","}))
+			if err := f.SetField(tt.field, tt.value); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := f.formData()
+			if err != nil {
+				t.Fatalf("formData() error = %v", err)
+			}
+			if got := data[tt.field]; got != tt.want {
+				t.Errorf("formData()[%s] = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package pdfprocessor
+
+import (
+	"context"
+	"log/slog"
+)
+
+// FieldsByPage returns the fields grouped by the number, from 1, of the first page
+// they appear on, each page's fields in document order. Fields whose page can't be
+// determined, e.g. in PDFs that store objects in compressed object streams, are
+// listed under page 0 and a warning is logged.
+func (f *PDFForm) FieldsByPage() map[int][]Field {
+	var pages map[string]int
+	objects, err := f.options.readObjects(context.Background(), f.inputPath)
+	if err != nil {
+		f.options.logEvent(slog.LevelWarn, "Could not determine field pages", "error", err)
+	} else {
+		pages = objects.fieldPages()
+	}
+
+	byPage := make(map[int][]Field)
+	for _, name := range f.order {
+		field, exists := f.fields[name]
+		if !exists {
+			continue
+		}
+		pdfName := name
+		if original, ok := f.pdfNames[name]; ok {
+			pdfName = original
+		}
+		page := pages[pdfName]
+		byPage[page] = append(byPage[page], field)
+	}
+	return byPage
+}
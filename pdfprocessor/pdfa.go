@@ -0,0 +1,69 @@
+package pdfprocessor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// pdfaParts maps the supported PDF/A conformance levels to their part numbers.
+// Ghostscript only produces level B conformance.
+var pdfaParts = map[string]string{
+	"1b": "1",
+	"2b": "2",
+	"3b": "3",
+}
+
+// WithPDFACompliance converts saved PDFs to the given PDF/A conformance level,
+// "1b", "2b" or "3b", for archival. The conversion runs Ghostscript (gs) after all
+// other output processing, and saving fails if the result does not declare the
+// requested conformance.
+func WithPDFACompliance(level string) Option {
+	return func(o *Options) {
+		o.PDFALevel = strings.ToLower(level)
+	}
+}
+
+// convertToPDFA converts the PDF at path in place to the configured PDF/A level.
+func (o Options) convertToPDFA(ctx context.Context, path string) error {
+	part, ok := pdfaParts[o.PDFALevel]
+	if !ok {
+		return fmt.Errorf("unsupported PDF/A level %q: use 1b, 2b or 3b", o.PDFALevel)
+	}
+
+	return rewriteFile(path, func(in, out string) error {
+		_, err := o.runCommand(ctx, "gs",
+			"-dPDFA="+part,
+			"-dBATCH",
+			"-dNOPAUSE",
+			"-dSAFER",
+			"-dQUIET",
+			"-dPDFACompatibilityPolicy=1",
+			"-sColorConversionStrategy=RGB",
+			"-sDEVICE=pdfwrite",
+			"-sOutputFile="+out,
+			in,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to convert to PDF/A-%s: %w", o.PDFALevel, err)
+		}
+		return checkPDFA(out, part)
+	})
+}
+
+// checkPDFA verifies that the XMP metadata of the PDF at path declares the PDF/A
+// part. Ghostscript falls back to plain PDF output when the input can't be made
+// compliant, so its exit status alone isn't enough.
+func checkPDFA(path, part string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF/A output: %w", err)
+	}
+	if !bytes.Contains(data, []byte("<pdfaid:part>"+part+"</pdfaid:part>")) &&
+		!bytes.Contains(data, []byte(`pdfaid:part="`+part+`"`)) {
+		return fmt.Errorf("conversion did not produce a PDF/A-%sB file", part)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	pdfObjectPattern = regexp.MustCompile(`(?s)(\d+\s+\d+)\s+obj\b(.*?)\bendobj`)
+	kidsPattern      = regexp.MustCompile(`(?s)/Kids\s*\[(.*?)\]`)
+	referencePattern = regexp.MustCompile(`(\d+\s+\d+)\s+R\b`)
+	titlePattern     = regexp.MustCompile(`/T\s*\(((?:[^()\\]|\\.)*)\)`)
+	parentPattern    = regexp.MustCompile(`/Parent\s+(\d+\s+\d+)\s+R\b`)
+	pagesPattern     = regexp.MustCompile(`/Pages\s+(\d+\s+\d+)\s+R\b`)
+	annotsPattern    = regexp.MustCompile(`(?s)/Annots\s*(\[.*?\]|\d+\s+\d+\s+R)`)
+	catalogPattern   = regexp.MustCompile(`/Type\s*/Catalog\b`)
+	pageTypePattern  = regexp.MustCompile(`/Type\s*/Page\b`)
+	objStmPattern    = regexp.MustCompile(`/Type\s*/(?:ObjStm|XRef)\b`)
+)
+
+// maxFieldDepth bounds how far parent and page tree links are followed, in case
+// a malformed PDF contains a cycle.
+const maxFieldDepth = 32
+
+// pdfObjects holds the bodies of the objects of an uncompressed PDF, keyed by
+// object number and generation, e.g. "12 0".
+type pdfObjects map[string]string
+
+// readObjects returns the objects of the PDF at path, using pdftk to uncompress it.
+func (o Options) readObjects(ctx context.Context, path string) (pdfObjects, error) {
+	output, err := o.runPDFTK(ctx, path, "output", "-", "uncompress")
+	if err != nil {
+		return nil, fmt.Errorf("failed to uncompress PDF: %w", err)
+	}
+	return parseObjects(string(output))
+}
+
+// parseObjects splits an uncompressed PDF into its objects. Objects inside object
+// streams can't be read, so PDFs using them or a cross-reference stream, as most
+// modern ones do, return ErrObjectStreams rather than an incomplete result.
+func parseObjects(pdf string) (pdfObjects, error) {
+	if objStmPattern.MatchString(pdf) {
+		return nil, ErrObjectStreams
+	}
+	objects := make(pdfObjects)
+	for _, match := range pdfObjectPattern.FindAllStringSubmatch(pdf, -1) {
+		objects[objectKey(match[1])] = match[2]
+	}
+	return objects, nil
+}
+
+// objectKey normalizes the spacing of an object number and generation.
+func objectKey(ref string) string {
+	return strings.Join(strings.Fields(ref), " ")
+}
+
+// references returns the keys of the objects referenced in s, in order.
+func references(s string) []string {
+	var keys []string
+	for _, match := range referencePattern.FindAllStringSubmatch(s, -1) {
+		keys = append(keys, objectKey(match[1]))
+	}
+	return keys
+}
+
+// pdfLiteralUnescaper decodes the common escapes of PDF literal strings.
+var pdfLiteralUnescaper = strings.NewReplacer(`\\`, `\`, `\(`, `(`, `\)`, `)`, `\r`, "\r", `\n`, "\n")
+
+// fieldName returns the fully qualified name of the field or widget object body,
+// joining its partial name with those of its parents.
+func (p pdfObjects) fieldName(body string) string {
+	var parts []string
+	for depth := 0; depth < maxFieldDepth; depth++ {
+		if title := titlePattern.FindStringSubmatch(body); title != nil {
+			parts = append([]string{pdfLiteralUnescaper.Replace(title[1])}, parts...)
+		}
+		parent := parentPattern.FindStringSubmatch(body)
+		if parent == nil {
+			break
+		}
+		body = p[objectKey(parent[1])]
+	}
+	return strings.Join(parts, ".")
+}
+
+// pages returns the keys of the page objects in document order.
+func (p pdfObjects) pages() []string {
+	var root string
+	for _, body := range p {
+		if catalogPattern.MatchString(body) {
+			if match := pagesPattern.FindStringSubmatch(body); match != nil {
+				root = objectKey(match[1])
+			}
+			break
+		}
+	}
+
+	var pages []string
+	var walk func(key string, depth int)
+	walk = func(key string, depth int) {
+		body, ok := p[key]
+		if !ok || depth > maxFieldDepth {
+			return
+		}
+		if pageTypePattern.MatchString(body) {
+			pages = append(pages, key)
+			return
+		}
+		if kids := kidsPattern.FindStringSubmatch(body); kids != nil {
+			for _, kid := range references(kids[1]) {
+				walk(kid, depth+1)
+			}
+		}
+	}
+	walk(root, 0)
+	return pages
+}
+
+// fieldPages maps the fully qualified name of each field with a widget annotation
+// to the number, from 1, of the first page showing it.
+func (p pdfObjects) fieldPages() map[string]int {
+	fieldPages := make(map[string]int)
+	for i, page := range p.pages() {
+		for _, annot := range p.pageAnnots(page) {
+			name := p.fieldName(p[annot])
+			if _, seen := fieldPages[name]; name != "" && !seen {
+				fieldPages[name] = i + 1
+			}
+		}
+	}
+	return fieldPages
+}
+
+// pageAnnots returns the keys of the annotations of a page object, in order.
+func (p pdfObjects) pageAnnots(page string) []string {
+	annots := annotsPattern.FindStringSubmatch(p[page])
+	if annots == nil {
+		return nil
+	}
+	list := annots[1]
+	if !strings.HasPrefix(list, "[") {
+		list = p[objectKey(strings.TrimSuffix(list, "R"))]
+	}
+	return references(list)
+}
@@ -0,0 +1,81 @@
+package pdfprocessor
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// uncompressedPDF is the object section of a two-page uncompressed PDF with a
+// link on page 1 and a field widget on page 2.
+const uncompressedPDF = `%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>
+endobj
+3 0 obj
+<< /Type /Page /Parent 2 0 R /Annots [5 0 R] >>
+endobj
+4 0 obj
+<< /Type /Page /Parent 2 0 R /Annots 7 0 R >>
+endobj
+5 0 obj
+<< /Type /Annot /Subtype /Link /Rect [10 20 110 40] >>
+endobj
+6 0 obj
+<< /Type /Annot /Subtype /Widget /T (name) /Rect [50 700 250 720.5] >>
+endobj
+7 0 obj
+[6 0 R]
+endobj
+`
+
+func TestParseObjects(t *testing.T) {
+	tests := []struct {
+		name            string
+		pdf             string
+		wantErr         error
+		wantAnnotations []Annotation
+		wantFieldPages  map[string]int
+	}{
+		{
+			name: "uncompressed",
+			pdf:  uncompressedPDF,
+			wantAnnotations: []Annotation{
+				{Type: "Link", Page: 1, Rect: [4]float64{10, 20, 110, 40}},
+				{Type: "Widget", Page: 2, Rect: [4]float64{50, 700, 250, 720.5}},
+			},
+			wantFieldPages: map[string]int{"name": 2},
+		},
+		{
+			name:    "object stream",
+			pdf:     uncompressedPDF + "8 0 obj\n<< /Type /ObjStm /N 3 /First 14 >>\nstream\nendstream\nendobj\n",
+			wantErr: ErrObjectStreams,
+		},
+		{
+			name:    "cross-reference stream",
+			pdf:     uncompressedPDF + "9 0 obj\n<< /Type/XRef /Size 10 >>\nstream\nendstream\nendobj\n",
+			wantErr: ErrObjectStreams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := parseObjects(tt.pdf)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseObjects() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := objects.annotations(); !reflect.DeepEqual(got, tt.wantAnnotations) {
+				t.Errorf("annotations() = %+v, want %+v", got, tt.wantAnnotations)
+			}
+			if got := objects.fieldPages(); !reflect.DeepEqual(got, tt.wantFieldPages) {
+				t.Errorf("fieldPages() = %v, want %v", got, tt.wantFieldPages)
+			}
+		})
+	}
+}
@@ -0,0 +1,526 @@
+package pdfprocessor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// commandWaitDelay bounds how long a command's output pipes are drained after it is killed.
+const commandWaitDelay = time.Second
+
+// runPDFTK runs pdftk with args and returns its combined output. The process and
+// any children it started are killed when ctx is done or the configured command
+// timeout expires, in which case an *ErrCommandTimeout is returned.
+// Transient I/O failures are retried up to the configured number of attempts.
+func (o Options) runPDFTK(ctx context.Context, args ...string) ([]byte, error) {
+	backoff := commandRetryBackoff
+	for attempt := 1; ; attempt++ {
+		output, err := o.runCommand(ctx, "pdftk", args...)
+		if err == nil || attempt >= o.CommandAttempts || !isTransient(output) {
+			return output, err
+		}
+		o.logEvent(slog.LevelWarn, "Retrying pdftk after transient failure", "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return output, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// commandRetryBackoff is the delay before the first pdftk retry; it doubles for each later retry.
+const commandRetryBackoff = 100 * time.Millisecond
+
+// transientErrors are messages of I/O failures that may succeed when retried,
+// e.g. on network file systems. Errors such as a file not being a form are not listed.
+var transientErrors = []string{
+	"Input/output error",
+	"Stale file handle",
+	"Resource temporarily unavailable",
+	"Device or resource busy",
+	"Interrupted system call",
+	"Connection timed out",
+}
+
+// isTransient reports whether pdftk output describes a failure worth retrying.
+func isTransient(output []byte) bool {
+	for _, message := range transientErrors {
+		if strings.Contains(string(output), message) {
+			return true
+		}
+	}
+	return false
+}
+
+// runCommand runs an external tool the way runPDFTK runs pdftk.
+func (o Options) runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if o.CommandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.CommandTimeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = commandWaitDelay
+	setProcessGroup(cmd)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return output, &ErrCommandTimeout{Command: name + " " + strings.Join(args, " "), Timeout: o.CommandTimeout}
+		}
+		if ctx.Err() != nil {
+			return output, ctx.Err()
+		}
+		return output, fmt.Errorf("%s error: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return output, nil
+}
+
+// pdftkPath validates a file path and makes it absolute before it is passed to
+// pdftk. pdftk has no "--" separator, so a relative path such as "-foo.pdf",
+// "A=foo.pdf" or "output" would be read as a flag, handle or keyword; absolute
+// paths can't be mistaken for any of these.
+func pdftkPath(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", fmt.Errorf("invalid path: empty path")
+	}
+	if strings.ContainsAny(path, "\x00\n\r") {
+		return "", fmt.Errorf("invalid path %q: contains control characters", path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	return abs, nil
+}
+
+// ensureOutputDir creates the directory of an output path if needed and checks
+// that it is writable, so write failures are reported clearly before pdftk runs.
+func ensureOutputDir(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("output directory %s cannot be created: %w", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("output directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+// atomicWrite calls write with a temporary path in the directory of path and
+// renames the result into place on success, so path is never left partially
+// written. On failure path is left untouched. The result keeps the mode of an
+// existing file at path, or gets 0666 less the umask like a newly created file.
+func atomicWrite(path string, write func(tempPath string) error) error {
+	temp, err := createExclusive(filepath.Dir(path), "."+filepath.Base(path)+".", ".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary output file: %w", err)
+	}
+	tempPath := temp.Name()
+	info, err := temp.Stat()
+	temp.Close()
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to stat temporary output file: %w", err)
+	}
+
+	mode := info.Mode().Perm()
+	if existing, err := os.Stat(path); err == nil {
+		mode = existing.Mode().Perm()
+	}
+
+	if err := write(tempPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	// write may replace the temporary file, e.g. through rewriteFile, losing its mode
+	if info, err := os.Stat(tempPath); err != nil || info.Mode().Perm() != mode {
+		if err := os.Chmod(tempPath, mode); err != nil {
+			os.Remove(tempPath)
+			return fmt.Errorf("failed to set output file permissions: %w", err)
+		}
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to move output file into place: %w", err)
+	}
+	return nil
+}
+
+// createExclusive creates a new file named prefix, a random number and suffix in
+// dir. Unlike os.CreateTemp it uses mode 0666, so the umask applies as it does
+// to any new file.
+func createExclusive(dir, prefix, suffix string) (*os.File, error) {
+	for try := 0; ; try++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(rand.Uint64(), 36)+suffix)
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && try < 10000 {
+			continue
+		}
+		return f, err
+	}
+}
+
+// fdfHeader and fdfFooter frame the field entries of the FDF file passed to pdftk.
+const (
+	fdfHeader = `%FDF-1.2
+%,,oe"
+1 0 obj
+<<
+/FDF << /Fields [`
+	fdfFooter = `]
+>>
+>>
+endobj
+trailer
+<<
+/Root 1 0 R
+>>
+%%EOF`
+)
+
+// writeFDF writes values as an FDF document, with fields sorted by name.
+func writeFDF(out io.Writer, values map[string]string) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := bufio.NewWriter(out)
+	w.WriteString(fdfHeader + "\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "<< /T %s /V %s>>\n", fdfString(name), fdfString(values[name]))
+	}
+	w.WriteString(fdfFooter + "\n")
+	return w.Flush()
+}
+
+// fillForm fills the template at in with values and writes the result to out,
+// flattening every field if flatten is set.
+func (o Options) fillForm(ctx context.Context, values map[string]string, in, out string, flatten bool) error {
+	fdfFile, err := os.CreateTemp("", "form-data-*.fdf")
+	if err != nil {
+		return fmt.Errorf("failed to create FDF file: %w", err)
+	}
+	defer o.removeTemp(fdfFile.Name())
+
+	err = writeFDF(fdfFile, values)
+	fdfFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write FDF file: %w", err)
+	}
+
+	args := []string{in, "fill_form", fdfFile.Name(), "output", out}
+	if flatten {
+		args = append(args, "flatten")
+	}
+	_, err = o.runPDFTK(ctx, args...)
+	return err
+}
+
+// fdfEscaper escapes the characters with special meaning in PDF literal strings.
+var fdfEscaper = strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", `\r`)
+
+// fdfString encodes s as a PDF string. ASCII text is written as an escaped literal
+// string; anything else as a UTF-16BE hex string with a byte order mark, which
+// viewers decode without depending on the PDF's default encoding.
+func fdfString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "(" + fdfEscaper.Replace(s) + ")"
+	}
+
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// standardInfoKeys are the document info entries removed by WithClearDocumentInfo.
+var standardInfoKeys = []string{"Title", "Author", "Subject", "Keywords", "Creator", "Producer"}
+
+// postProcess applies the configured output transformations to a filled PDF in place.
+func (f *PDFForm) postProcess(ctx context.Context, path string) error {
+	// Rotate first, while page numbers still match the template
+	if len(f.rotations) > 0 {
+		if err := f.rotatePages(ctx, path); err != nil {
+			return err
+		}
+	}
+	if len(f.pages) > 0 {
+		err := f.options.rewritePDF(ctx, path, func(in, out string) []string {
+			args := append([]string{in, "cat"}, f.pages...)
+			return append(args, "output", out)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if overflows := f.overflows(); len(overflows) > 0 {
+		if err := f.appendAddendum(ctx, path, overflows); err != nil {
+			return err
+		}
+	}
+	if len(f.removed) > 0 {
+		if err := f.removeAnnotations(ctx, path); err != nil {
+			return err
+		}
+	}
+	if f.watermark != nil {
+		if err := f.applyWatermark(ctx, path); err != nil {
+			return err
+		}
+	}
+	if len(f.attached) > 0 {
+		if err := f.attachFiles(ctx, path); err != nil {
+			return err
+		}
+	}
+	if len(f.options.DocumentInfo) > 0 || f.options.ClearDocumentInfo {
+		if err := f.updateInfo(ctx, path); err != nil {
+			return err
+		}
+	}
+	// Conversion must come last, as any later rewrite would break compliance
+	if f.options.PDFALevel != "" {
+		if err := f.options.convertToPDFA(ctx, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendTo fills the form and writes basePDF followed by the filled pages to output.
+// The filled form is flattened, so the appended pages carry the values as page
+// content rather than editable fields; fields in basePDF are left as they are.
+// Unlike Save, it does not reset IsDirty.
+func (f *PDFForm) AppendTo(basePDF, output string) error {
+	basePDF, err := pdftkPath(basePDF)
+	if err != nil {
+		return err
+	}
+	if output, err = pdftkPath(output); err != nil {
+		return err
+	}
+	if err := ensureOutputDir(output); err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "pdf-append-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer f.options.removeTemp(tmpDir)
+
+	filled := filepath.Join(tmpDir, "filled.pdf")
+	if err := f.writeFilled(context.Background(), filled); err != nil {
+		return err
+	}
+
+	_, err = f.options.runPDFTK(context.Background(), "A="+basePDF, "B="+filled, "cat", "A", "B", "output", output)
+	return err
+}
+
+// MergeForms renders each form to PDF and concatenates them in order, e.g. an
+// HTML cover page followed by a filled PDF form. HTML forms that have not been
+// rendered with GeneratePDF are rendered first.
+func MergeForms(forms ...FormProcessor) ([]byte, error) {
+	if len(forms) == 0 {
+		return nil, fmt.Errorf("no forms to merge")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "pdf-merge-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	args := make([]string, 0, len(forms)+3)
+	for i, form := range forms {
+		path := filepath.Join(tmpDir, fmt.Sprintf("part-%d.pdf", i))
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		_, err = form.WriteTo(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to render form %d: %w", i+1, err)
+		}
+		args = append(args, path)
+	}
+
+	output := filepath.Join(tmpDir, "merged.pdf")
+	args = append(args, "cat", "output", output)
+	if _, err := newOptions(nil).runPDFTK(context.Background(), args...); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read merged PDF: %w", err)
+	}
+	return data, nil
+}
+
+// SelectPages keeps only the given pages of the output when the form is saved.
+// ranges is a comma-separated list of pages and inclusive ranges, e.g. "1-2,4".
+func (f *PDFForm) SelectPages(ranges string) error {
+	total, err := f.options.pageCount(context.Background(), f.inputPath)
+	if err != nil {
+		return err
+	}
+
+	var pages []string
+	for _, part := range strings.Split(ranges, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return fmt.Errorf("invalid page range %q: empty range", ranges)
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return fmt.Errorf("invalid page range %q: %s is not a page number", ranges, bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return fmt.Errorf("invalid page range %q: %s is not a page number", ranges, bounds[1])
+			}
+		}
+
+		if first < 1 || last < first {
+			return fmt.Errorf("invalid page range %q: %s", ranges, part)
+		}
+		if last > total {
+			return fmt.Errorf("page %d is beyond the document length of %d pages", last, total)
+		}
+		pages = append(pages, fmt.Sprintf("%d-%d", first, last))
+	}
+
+	f.pages = pages
+	f.pdfData = nil
+	return nil
+}
+
+// pageCount returns the number of pages in the PDF at path.
+func (o Options) pageCount(ctx context.Context, path string) (int, error) {
+	output, err := o.runPDFTK(ctx, path, "dump_data")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "NumberOfPages: "); ok {
+			return strconv.Atoi(value)
+		}
+	}
+	return 0, fmt.Errorf("pdftk did not report a page count")
+}
+
+// updateInfo writes the configured document info entries into the PDF at path.
+func (f *PDFForm) updateInfo(ctx context.Context, path string) error {
+	info := make(map[string]string)
+	if f.options.ClearDocumentInfo {
+		// pdftk removes info entries whose value is empty
+		for _, key := range standardInfoKeys {
+			info[key] = ""
+		}
+	}
+	for key, value := range f.options.DocumentInfo {
+		info[key] = value
+	}
+
+	keys := make([]string, 0, len(info))
+	for key := range info {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString("InfoBegin\n")
+		sb.WriteString(fmt.Sprintf("InfoKey: %s\n", key))
+		sb.WriteString(fmt.Sprintf("InfoValue: %s\n", info[key]))
+	}
+
+	infoFile, err := os.CreateTemp("", "pdf-info-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create info file: %w", err)
+	}
+	defer f.options.removeTemp(infoFile.Name())
+
+	if _, err := infoFile.WriteString(sb.String()); err != nil {
+		infoFile.Close()
+		return fmt.Errorf("failed to write info file: %w", err)
+	}
+	infoFile.Close()
+
+	return f.options.rewritePDF(ctx, path, func(in, out string) []string {
+		return []string{in, "update_info_utf8", infoFile.Name(), "output", out}
+	})
+}
+
+// rewritePDF runs pdftk with the arguments built from the input path and a
+// temporary output path, then replaces the file at path with the result.
+func (o Options) rewritePDF(ctx context.Context, path string, args func(in, out string) []string) error {
+	return rewriteFile(path, func(in, out string) error {
+		_, err := o.runPDFTK(ctx, args(in, out)...)
+		return err
+	})
+}
+
+// rewriteFile calls rewrite with the path of a PDF and a temporary output path,
+// then replaces the file at path with the result.
+func rewriteFile(path string, rewrite func(in, out string) error) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "pdftk-*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+
+	if err := rewrite(path, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace PDF: %w", err)
+	}
+	return nil
+}
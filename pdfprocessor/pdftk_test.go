@@ -0,0 +1,231 @@
+package pdfprocessor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPDFTKPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "leading dash", path: "-foo.pdf"},
+		{name: "handle assignment", path: "A=foo.pdf"},
+		{name: "output keyword", path: "output"},
+		{name: "flatten keyword", path: "flatten"},
+		{name: "empty", path: "", wantErr: true},
+		{name: "blank", path: "  ", wantErr: true},
+		{name: "NUL", path: "foo\x00.pdf", wantErr: true},
+		{name: "newline", path: "foo\n.pdf", wantErr: true},
+		{name: "carriage return", path: "foo\r.pdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pdftkPath(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("pdftkPath(%q) = %q, want error", tt.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pdftkPath(%q) returned error: %v", tt.path, err)
+			}
+			if !filepath.IsAbs(got) {
+				t.Errorf("pdftkPath(%q) = %q, want absolute path", tt.path, got)
+			}
+			if filepath.Base(got) != tt.path {
+				t.Errorf("pdftkPath(%q) = %q, want file name %q", tt.path, got, tt.path)
+			}
+		})
+	}
+}
+
+func TestFDFString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "John Doe", want: "(John Doe)"},
+		{name: "empty", in: "", want: "()"},
+		{name: "parentheses", in: "Smith (Jr.)", want: `(Smith \(Jr.\))`},
+		{name: "unbalanced parenthesis", in: "a)b", want: `(a\)b)`},
+		{name: "backslash", in: `C:\forms`, want: `(C:\\forms)`},
+		{name: "carriage return", in: "a\rb", want: `(a\rb)`},
+		{name: "latin accent", in: "José", want: "<FEFF004A006F007300E9>"},
+		{name: "CJK", in: "名前", want: "<FEFF540D524D>"},
+		{name: "outside BMP", in: "a😀", want: "<FEFF0061D83DDE00>"},
+		{name: "non-ASCII with parentheses", in: "(é)", want: "<FEFF002800E90029>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fdfString(tt.in); got != tt.want {
+				t.Errorf("fdfString(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFDF(t *testing.T) {
+	values := map[string]string{
+		"Name":    "Smith (Jr.)",
+		"City":    "São Paulo",
+		"Path\\1": `a\b`,
+	}
+
+	var buf bytes.Buffer
+	if err := writeFDF(&buf, values); err != nil {
+		t.Fatalf("writeFDF returned error: %v", err)
+	}
+
+	want := fdfHeader + "\n" +
+		"<< /T (City) /V <FEFF005300E3006F0020005000610075006C006F>>>\n" +
+		"<< /T (Name) /V (Smith \\(Jr.\\))>>\n" +
+		"<< /T (Path\\\\1) /V (a\\\\b)>>\n" +
+		fdfFooter + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeFDF wrote:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAtomicWriteMode(t *testing.T) {
+	// A file created directly shows the mode the umask leaves for a new output
+	direct := filepath.Join(t.TempDir(), "direct")
+	if err := os.WriteFile(direct, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(direct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newMode := info.Mode().Perm()
+
+	inPlace := func(tempPath string) error { return os.WriteFile(tempPath, []byte("%PDF"), 0600) }
+	// replace swaps in a new file the way rewriteFile does
+	replace := func(tempPath string) error {
+		other := tempPath + ".new"
+		if err := os.WriteFile(other, []byte("%PDF"), 0600); err != nil {
+			return err
+		}
+		return os.Rename(other, tempPath)
+	}
+
+	tests := []struct {
+		name     string
+		existing os.FileMode // Mode of the file already at the path, zero for none
+		write    func(tempPath string) error
+		want     os.FileMode
+	}{
+		{name: "new file", write: inPlace, want: newMode},
+		{name: "new file replaced by write", write: replace, want: newMode},
+		{name: "existing file", existing: 0640, write: inPlace, want: 0640},
+		{name: "existing file replaced by write", existing: 0604, write: replace, want: 0604},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "out.pdf")
+			if tt.existing != 0 {
+				if err := os.WriteFile(path, nil, tt.existing); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chmod(path, tt.existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := atomicWrite(path, tt.write); err != nil {
+				t.Fatalf("atomicWrite() error = %v", err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode().Perm(); got != tt.want {
+				t.Errorf("mode = %o, want %o", got, tt.want)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("directory has %d entries, want only the output", len(entries))
+			}
+		})
+	}
+}
+
+// installPDFTK puts a pdftk shell script with the given body on PATH and
+// returns its directory.
+func installPDFTK(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake pdftk is a shell script")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pdftk"), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+// fakePDFTK puts a pdftk script on PATH that fails with output for the first
+// failures runs and then succeeds, and returns the file counting its runs.
+func fakePDFTK(t *testing.T, failures int, output string) string {
+	t.Helper()
+	count := filepath.Join(t.TempDir(), "runs")
+	installPDFTK(t, fmt.Sprintf(`echo run >> %q
+if [ "$(wc -l < %q)" -le %d ]; then
+	echo %q
+	exit 1
+fi
+`, count, count, failures, output))
+	return count
+}
+
+func TestRunPDFTKRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		output   string
+		wantErr  bool
+		wantRuns int
+	}{
+		{name: "success", failures: 0, wantRuns: 1},
+		{name: "transient failure retried", failures: 1, output: "Input/output error", wantRuns: 2},
+		{name: "transient failures exhaust attempts", failures: defaultCommandAttempts, output: "Stale file handle", wantErr: true, wantRuns: defaultCommandAttempts},
+		{name: "permanent failure not retried", failures: 1, output: "Error: Unexpected Exception", wantErr: true, wantRuns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := fakePDFTK(t, tt.failures, tt.output)
+
+			// Default options, as used by package-level functions such as MergeForms
+			_, err := newOptions([]Option{WithLogger(nil)}).runPDFTK(context.Background(), "dump_data")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runPDFTK() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			runs, err := os.ReadFile(count)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bytes.Count(runs, []byte("\n")); got != tt.wantRuns {
+				t.Errorf("pdftk ran %d times, want %d", got, tt.wantRuns)
+			}
+		})
+	}
+}
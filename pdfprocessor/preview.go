@@ -0,0 +1,55 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// PreviewPNG fills the form and renders one page, numbered from 1, to a PNG image
+// at the given resolution in dots per inch. It requires pdftoppm from poppler-utils.
+func (f *PDFForm) PreviewPNG(page int, dpi int) ([]byte, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("invalid page %d: pages are numbered from 1", page)
+	}
+	if dpi < 1 {
+		return nil, fmt.Errorf("invalid resolution %d dpi", dpi)
+	}
+
+	data, err := f.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "preview-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create preview directory: %w", err)
+	}
+	defer f.options.removeTemp(tempDir)
+
+	input := filepath.Join(tempDir, "form.pdf")
+	if err := os.WriteFile(input, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write preview input: %w", err)
+	}
+
+	ctx := context.Background()
+	if pages, err := f.options.pageCount(ctx, input); err == nil && page > pages {
+		return nil, fmt.Errorf("invalid page %d: form has %d pages", page, pages)
+	}
+
+	// With -singlefile, pdftoppm writes exactly <prefix>.png
+	prefix := filepath.Join(tempDir, "page")
+	number := strconv.Itoa(page)
+	if _, err := f.options.runCommand(ctx, "pdftoppm", "-png", "-singlefile",
+		"-r", strconv.Itoa(dpi), "-f", number, "-l", number, input, prefix); err != nil {
+		return nil, fmt.Errorf("failed to render preview: %w", err)
+	}
+
+	image, err := os.ReadFile(prefix + ".png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preview: %w", err)
+	}
+	return image, nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package pdfprocessor
+
+import "os/exec"
+
+// setProcessGroup is a no-op on platforms without process groups; cancelling
+// cmd kills only the pdftk process itself.
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -3,19 +3,30 @@
 package pdfprocessor
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
+	"path"
+	"reflect"
+	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
-	"github.com/desertbit/fillpdf"
 	service "github.com/josephmowjew/go-form-processor/pdfprocessor/services"
 	"github.com/josephmowjew/go-form-processor/types"
 )
@@ -32,21 +43,61 @@ const (
 	Choice
 )
 
+// ValidationMode controls how strictly values are checked.
+type ValidationMode int
+
+const (
+	// Strict rejects values of the wrong type and runs every validation rule.
+	Strict ValidationMode = iota
+	// Lenient converts values with ConvertFieldValue and checks required fields,
+	// ranges and options, but skips the rules added with AddRule.
+	Lenient
+)
+
 // Field represents a single form field in a PDF document.
 type Field struct {
-	Name     string      // Name of the field in the PDF
-	Type     FieldType   // Type of the field
-	Options  []string    // Available options for Choice fields
-	Required bool        // Whether the field is required
-	Value    interface{} // Current value of the field
+	Name         string      // Name of the field in the PDF
+	Tooltip      string      // User-facing description of the field (the PDF's TU entry), if any
+	Type         FieldType   // Type of the field
+	Options      []string    // Available options for Choice fields
+	OptionLabels []string    // Display labels for Options, in the same order
+	Required     bool        // Whether the field is required
+	MaxLength    int         // Maximum length of Text fields, zero if unlimited or unknown
+	DefaultValue interface{} // Default value defined by the template, nil if none
+	Value        interface{} // Current value of the field
 }
 
 // PDFForm represents a PDF form with its fields and configuration.
 type PDFForm struct {
 	fields    map[string]Field
+	order     []string // Field names in the order pdftk reported them
 	inputPath string
 	inputURL  string
+	tempInput bool // Whether inputPath is a temporary copy removed with the form
 	options   Options
+
+	conditions map[string]func(f *PDFForm) bool    // Conditional required rules keyed by field name
+	rules      []rule                              // Cross-field validation rules in the order added
+	pages      []string                            // pdftk page ranges kept on output, nil keeps all pages
+	watermark  *watermark                          // Watermark stamped on output, if any
+	pdfData    []byte                              // Filled PDF rendered by GeneratePDF, cleared when the form changes
+	pdfNames   map[string]string                   // Template field names of renamed fields, keyed by current name
+	duplicates map[string]int                      // Number of definitions of field names defined more than once
+	history    map[string][]HistoryEntry           // Values set on each field, oldest first, when history is enabled
+	locked     []string                            // Template names of fields locked instead of flattening the whole form
+	ranges     map[string]numericRange             // Allowed ranges of numeric text fields, keyed by field name
+	formatters map[string]func(interface{}) string // Output formatters of field values, keyed by field name
+	folded     map[string]string                   // Field names keyed by their lowercase form, with WithCaseInsensitiveFields
+	attached   []attachment                        // Files embedded in the output, in the order attached
+	removed    []string                            // Annotation types removed from the output
+	rotations  map[int]int                         // Clockwise rotations in degrees of output pages, keyed by template page number
+	dirty      map[string]bool                     // Names of fields set since the form was loaded or last saved or uploaded
+}
+
+// rule is a named cross-field validation rule.
+type rule struct {
+	name string
+	fn   func(f *PDFForm) error
 }
 
 // Options configures the behavior of the PDF form processor.
@@ -54,11 +105,72 @@ type Options struct {
 	ValidateOnSet bool             // Whether to validate fields when they are set
 	Logger        *log.Logger      // Logger for processing information
 	Uploader      service.Uploader // Uploader service for direct PDF uploads
+
+	IgnoreUnknownFields  bool                    // Whether setting a field missing from the form is skipped instead of failing
+	FetchHeaders         http.Header             // Extra headers sent when downloading forms from a URL
+	DocumentInfo         map[string]string       // PDF info dictionary entries (Title, Author, ...) set on output
+	ClearDocumentInfo    bool                    // Whether standard info entries inherited from the template are removed
+	KeepTempFiles        bool                    // Whether intermediate files are kept and logged for debugging
+	AutoConvert          bool                    // Whether SetField converts values to the field's type before checking them
+	ValidationMode       ValidationMode          // Whether values are checked strictly or leniently
+	LooseOptionMatching  bool                    // Whether choice options match ignoring case and whitespace
+	Slog                 *slog.Logger            // Structured logger, used instead of Logger when set
+	CommandTimeout       time.Duration           // Maximum run time of each pdftk invocation, zero for no limit
+	FieldNameTransform   func(string) string     // Maps PDF field names to the names used by the form
+	DisableFinalizer     bool                    // Whether temporary input files are left for Close instead of the garbage collector
+	MaxDownloadSize      int64                   // Maximum size in bytes of forms downloaded from a URL, zero or less for no limit
+	HTTPClient           *http.Client            // Client used for downloads, defaults to http.DefaultClient
+	Defaults             map[string]interface{}  // Values set on fields right after the form is loaded
+	RequiredOverrides    map[string]bool         // Required flags that replace the ones parsed from the form, keyed by field name
+	CommandAttempts      int                     // Maximum number of runs of a pdftk invocation that fails with a transient I/O error
+	NumberFormats        map[string]NumberFormat // Formats of numbers written to text fields, keyed by field name
+	FallbackRenderer     HTMLRenderer            // Converts HTML forms to PDF when Chrome is not installed
+	CSS                  []string                // Stylesheets added to HTML forms before PDF generation
+	StylesheetURLs       []string                // External stylesheets linked into HTML forms before PDF generation
+	DisableDefaultStyles bool                    // Whether the built-in styles are left out of HTML forms
+	TemplateExecution    bool                    // Whether HTML forms are executed as Go templates before filling
+	FieldTypeOverrides   map[string]FieldType    // Field types that replace the ones detected from the form, keyed by field name
+	PDFALevel            string                  // PDF/A conformance level of saved PDFs, e.g. "2b", empty for plain PDF
+	ValidationSummary    bool                    // Whether Validate reports every failure instead of stopping at the first
+	CheckRedirect        RedirectPolicy          // Redirect policy for downloads
+	Downloader           Downloader              // Source of forms loaded from a URL, defaults to an HTTP GET
+	History              bool                    // Whether every value set on a field is recorded
+	CaseInsensitiveNames bool                    // Whether field names are matched ignoring case
+	IncrementalSave      bool                    // Whether values are appended as an incremental update instead of rewriting the PDF
+	Fonts                []string                // Font files declared in HTML forms before PDF generation
+	TextDirection        string                  // dir attribute set on HTML forms before PDF generation, e.g. "rtl"
+
+	// BooleanMappings holds the states written to boolean fields for true and
+	// false, keyed by field name.
+	BooleanMappings map[string]BooleanMapping
+
+	// OverflowAddendum moves text beyond a field's maximum length onto an
+	// addendum page appended to the output.
+	OverflowAddendum bool
 }
 
 // Option is a function that configures Options.
 type Option func(*Options)
 
+// defaultMaxDownloadSize is the default limit on forms downloaded from a URL.
+const defaultMaxDownloadSize = 50 << 20
+
+// defaultCommandAttempts is the default number of runs of a failing pdftk invocation.
+const defaultCommandAttempts = 2
+
+// newOptions returns the default options with opts applied.
+func newOptions(opts []Option) Options {
+	options := Options{
+		Logger:          log.Default(),
+		MaxDownloadSize: defaultMaxDownloadSize,
+		CommandAttempts: defaultCommandAttempts,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
 // WithValidation enables validation when setting field values.
 func WithValidation() Option {
 	return func(o *Options) {
@@ -80,13 +192,306 @@ func WithUploader(uploader service.Uploader) Option {
 	}
 }
 
+// WithIgnoreUnknownFields skips and logs values for fields that are not in the form
+// instead of returning an error, so one data map can be used across templates.
+func WithIgnoreUnknownFields() Option {
+	return func(o *Options) {
+		o.IgnoreUnknownFields = true
+	}
+}
+
+// WithFetchHeaders adds headers to the requests used to download forms from a URL.
+func WithFetchHeaders(headers http.Header) Option {
+	return func(o *Options) {
+		if o.FetchHeaders == nil {
+			o.FetchHeaders = make(http.Header)
+		}
+		for key, values := range headers {
+			for _, value := range values {
+				o.FetchHeaders.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithFetchAuth sends a bearer token when downloading forms from a URL.
+func WithFetchAuth(token string) Option {
+	return func(o *Options) {
+		if o.FetchHeaders == nil {
+			o.FetchHeaders = make(http.Header)
+		}
+		o.FetchHeaders.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithUserAgent sets the User-Agent header used when downloading forms from a URL.
+func WithUserAgent(userAgent string) Option {
+	return func(o *Options) {
+		if o.FetchHeaders == nil {
+			o.FetchHeaders = make(http.Header)
+		}
+		o.FetchHeaders.Set("User-Agent", userAgent)
+	}
+}
+
+// RedirectPolicy decides whether to follow a redirect, as http.Client.CheckRedirect does.
+type RedirectPolicy func(req *http.Request, via []*http.Request) error
+
+// WithCheckRedirect sets the redirect policy used when downloading forms from a URL,
+// as in http.Client.CheckRedirect. By default headers such as Authorization are
+// dropped on redirects to another host; see ForwardHeadersOnRedirect.
+func WithCheckRedirect(policy RedirectPolicy) Option {
+	return func(o *Options) {
+		o.CheckRedirect = policy
+	}
+}
+
+// maxRedirects is the number of redirects ForwardHeadersOnRedirect follows, as for http.Client.
+const maxRedirects = 10
+
+// ForwardHeadersOnRedirect is a redirect policy for WithCheckRedirect that sends the
+// original request's headers, including Authorization, to every redirect target,
+// even on another host. Only use it when every redirect target is trusted.
+func ForwardHeadersOnRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	for key, values := range via[0].Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	return nil
+}
+
+// WithDocumentInfo sets PDF info dictionary entries such as Title, Author,
+// Subject and Keywords on the filled output.
+func WithDocumentInfo(info map[string]string) Option {
+	return func(o *Options) {
+		if o.DocumentInfo == nil {
+			o.DocumentInfo = make(map[string]string)
+		}
+		for key, value := range info {
+			o.DocumentInfo[key] = value
+		}
+	}
+}
+
+// WithClearDocumentInfo removes the standard info dictionary entries inherited
+// from the template. Entries set with WithDocumentInfo are still written.
+func WithClearDocumentInfo() Option {
+	return func(o *Options) {
+		o.ClearDocumentInfo = true
+	}
+}
+
+// WithKeepTempFiles keeps intermediate files such as the filled PDF produced
+// before upload, and logs their paths, to help diagnose malformed output.
+func WithKeepTempFiles() Option {
+	return func(o *Options) {
+		o.KeepTempFiles = true
+	}
+}
+
+// WithAutoConvert makes SetField convert values with ConvertFieldValue before
+// type checking, so "yes" sets a Boolean field and 30 sets a Text field.
+func WithAutoConvert() Option {
+	return func(o *Options) {
+		o.AutoConvert = true
+	}
+}
+
+// WithLooseOptionMatching makes choice values match options regardless of case
+// and stray whitespace, so "yes" is accepted for an option defined as " Yes ".
+// The option is stored exactly as the form defines it.
+func WithLooseOptionMatching() Option {
+	return func(o *Options) {
+		o.LooseOptionMatching = true
+	}
+}
+
+// WithValidationSummaryLogger makes Validate check everything in one pass, logging
+// each failure through the configured logger, and return all failures joined into
+// one error instead of stopping at the first.
+func WithValidationSummaryLogger() Option {
+	return func(o *Options) {
+		o.ValidationSummary = true
+	}
+}
+
+// WithValidationMode sets how strictly values are checked. Lenient mode suits data
+// entry, while Strict mode (the default) suits final submission.
+func WithValidationMode(mode ValidationMode) Option {
+	return func(o *Options) {
+		o.ValidationMode = mode
+	}
+}
+
+// WithCommandTimeout limits how long each pdftk invocation may run. pdftk is
+// killed when the timeout expires and an *ErrCommandTimeout is returned.
+func WithCommandTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.CommandTimeout = timeout
+	}
+}
+
+// WithCommandAttempts sets how many times a pdftk invocation is run when it fails
+// with a transient I/O error, such as on an NFS-mounted temp directory. The
+// default is 2; use 1 to disable retries. Other failures are never retried.
+func WithCommandAttempts(attempts int) Option {
+	return func(o *Options) {
+		o.CommandAttempts = attempts
+	}
+}
+
+// WithFieldNameTransform canonicalizes PDF field names when the form is loaded,
+// e.g. converting "Owner_Name_1" to "ownerName". All methods use the transformed
+// names, while the filled PDF is always written with the original field names.
+// The transform must map distinct names to distinct results; a collision makes
+// the constructor fail. It applies to PDF forms only.
+func WithFieldNameTransform(transform func(string) string) Option {
+	return func(o *Options) {
+		o.FieldNameTransform = transform
+	}
+}
+
+// WithoutFinalizer stops forms loaded from a URL or fs.FS from removing their
+// temporary input file when garbage collected. Call Close to remove it.
+func WithoutFinalizer() Option {
+	return func(o *Options) {
+		o.DisableFinalizer = true
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to download forms and verify uploads,
+// e.g. one whose transport routes through a corporate proxy. The default client
+// honors the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		o.HTTPClient = client
+	}
+}
+
+// httpClient returns the configured HTTP client or the default one.
+func (o Options) httpClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// WithMaxDownloadSize limits the size of forms downloaded from a URL. The
+// default is 50MB; a limit of zero or less disables the check.
+func WithMaxDownloadSize(bytes int64) Option {
+	return func(o *Options) {
+		o.MaxDownloadSize = bytes
+	}
+}
+
+// WithDefaults pre-populates fields with values shared across records. Defaults are
+// set with SetField right after the fields are loaded, so an invalid default makes
+// the constructor fail.
+func WithDefaults(defaults map[string]interface{}) Option {
+	return func(o *Options) {
+		if o.Defaults == nil {
+			o.Defaults = make(map[string]interface{})
+		}
+		for name, value := range defaults {
+			o.Defaults[name] = value
+		}
+	}
+}
+
+// WithRequiredFields marks the named fields as required, overriding the flags
+// parsed from the form, for templates whose required flags are unreliable.
+func WithRequiredFields(names []string) Option {
+	return func(o *Options) {
+		o.setRequired(names, true)
+	}
+}
+
+// WithOptionalFields marks the named fields as optional, overriding the flags
+// parsed from the form.
+func WithOptionalFields(names []string) Option {
+	return func(o *Options) {
+		o.setRequired(names, false)
+	}
+}
+
+// setRequired records required flag overrides for the named fields.
+func (o *Options) setRequired(names []string, required bool) {
+	if o.RequiredOverrides == nil {
+		o.RequiredOverrides = make(map[string]bool)
+	}
+	for _, name := range names {
+		o.RequiredOverrides[name] = required
+	}
+}
+
+// WithFieldTypeOverrides sets the types of the named fields, overriding the types
+// detected from the form, for fields pdftk classifies wrongly.
+func WithFieldTypeOverrides(overrides map[string]FieldType) Option {
+	return func(o *Options) {
+		if o.FieldTypeOverrides == nil {
+			o.FieldTypeOverrides = make(map[string]FieldType)
+		}
+		for name, fieldType := range overrides {
+			o.FieldTypeOverrides[name] = fieldType
+		}
+	}
+}
+
+// overrideFields applies the required flag and type overrides to loaded fields.
+func (o Options) overrideFields(fields map[string]Field) {
+	for name, required := range o.RequiredOverrides {
+		field, exists := fields[name]
+		if !exists {
+			o.logEvent(slog.LevelWarn, "Required override names unknown field", "field", name)
+			continue
+		}
+		field.Required = required
+		fields[name] = field
+	}
+
+	for name, fieldType := range o.FieldTypeOverrides {
+		field, exists := fields[name]
+		if !exists {
+			o.logEvent(slog.LevelWarn, "Type override names unknown field", "field", name)
+			continue
+		}
+		field.Type = fieldType
+		fields[name] = field
+	}
+}
+
+// applyDefaults sets the configured default values on a newly loaded form.
+func applyDefaults(form FormProcessor, defaults map[string]interface{}) error {
+	var errors []string
+	for name, value := range defaults {
+		if err := form.SetField(name, value); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("invalid default values: %s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// removeTemp removes an intermediate file or directory, or logs its path if temp files are kept.
+func (o Options) removeTemp(path string) {
+	if !o.KeepTempFiles {
+		os.RemoveAll(path)
+		return
+	}
+	o.logEvent(slog.LevelInfo, "Keeping temporary file", "path", path)
+}
+
 // NewForm creates a new PDFForm instance with the specified input path and options.
 func NewForm(inputPath string, opts ...Option) (*PDFForm, error) {
-	options := Options{
-		Logger: log.Default(),
-	}
-	for _, opt := range opts {
-		opt(&options)
+	options := newOptions(opts)
+
+	inputPath, err := pdftkPath(inputPath)
+	if err != nil {
+		return nil, err
 	}
 
 	form := &PDFForm{
@@ -99,26 +504,62 @@ func NewForm(inputPath string, opts ...Option) (*PDFForm, error) {
 		return nil, fmt.Errorf("failed to load form fields: %w", err)
 	}
 
+	if err := applyDefaults(form, options.Defaults); err != nil {
+		return nil, err
+	}
+	form.markClean()
+
 	return form, nil
 }
 
 // NewFormFromURL creates a new PDFForm instance from a URL with the specified options.
 func NewFormFromURL(url string, opts ...Option) (*PDFForm, error) {
+	options := newOptions(opts)
+
 	// Download the file to a temporary location
-	resp, err := http.Get(url)
+	download, err := options.download(context.Background(), url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download PDF: %w", err)
 	}
-	defer resp.Body.Close()
+	defer download.Close()
+
+	// Make sure we got a PDF rather than, say, an HTML login page
+	body := bufio.NewReader(download)
+	header, _ := body.Peek(pdfHeaderWindow)
+	if !isPDF(header) {
+		return nil, fmt.Errorf("download failed: response is not a PDF (Content-Type %q)", download.contentType)
+	}
+
+	return newFormFromReader(body, url, options)
+}
+
+// NewFormFromFS creates a new PDFForm instance from a file in fsys, such as an
+// embedded filesystem. The file is copied to a temporary location because pdftk
+// requires a real path.
+func NewFormFromFS(fsys fs.FS, name string, opts ...Option) (*PDFForm, error) {
+	options := newOptions(opts)
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer file.Close()
+
+	return newFormFromReader(file, "", options)
+}
 
+// newFormFromReader copies r to a temporary file and loads a form from it.
+// The temporary file is removed by Close, or when the form is garbage collected
+// unless the finalizer is disabled.
+func newFormFromReader(r io.Reader, url string, options Options) (*PDFForm, error) {
 	// Create a temporary file
 	tmpFile, err := os.CreateTemp("", "pdf-form-*.pdf")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary file: %w", err)
 	}
 
-	// Copy the response body to the temporary file
-	_, err = io.Copy(tmpFile, resp.Body)
+	// Copy the PDF to the temporary file
+	_, err = io.Copy(tmpFile, r)
 	if err != nil {
 		tmpFile.Close()
 		os.Remove(tmpFile.Name())
@@ -126,196 +567,956 @@ func NewFormFromURL(url string, opts ...Option) (*PDFForm, error) {
 	}
 	tmpFile.Close()
 
-	options := Options{
-		Logger: log.Default(),
-	}
-	for _, opt := range opts {
-		opt(&options)
+	form := &PDFForm{
+		inputPath: tmpFile.Name(),
+		inputURL:  url,
+		tempInput: true,
+		fields:    make(map[string]Field),
+		options:   options,
+	}
+
+	if err := form.loadFields(); err != nil {
+		os.Remove(tmpFile.Name())
+		return nil, fmt.Errorf("failed to load form fields: %w", err)
+	}
+
+	if err := applyDefaults(form, options.Defaults); err != nil {
+		os.Remove(tmpFile.Name())
+		return nil, err
+	}
+	form.markClean()
+
+	// Add cleanup function to the form
+	if !options.DisableFinalizer {
+		runtime.SetFinalizer(form, func(f *PDFForm) {
+			if f.tempInput && f.inputPath != "" {
+				os.Remove(f.inputPath)
+			}
+		})
+	}
+
+	return form, nil
+}
+
+// Close removes the temporary copy of the input PDF made by NewFormFromURL or
+// NewFormFromFS. It does nothing for forms loaded from a path with NewForm.
+// The form must not be saved or uploaded after it is closed.
+func (f *PDFForm) Close() error {
+	runtime.SetFinalizer(f, nil)
+	if !f.tempInput || f.inputPath == "" {
+		return nil
+	}
+
+	err := os.Remove(f.inputPath)
+	f.tempInput = false
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove temporary PDF: %w", err)
+	}
+	return nil
+}
+
+// fetch performs a GET request for url with the configured fetch headers.
+// Responses with a non-2xx status are closed and returned as errors.
+func fetch(ctx context.Context, url string, options Options) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range options.FetchHeaders {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	client := options.httpClient()
+	if options.CheckRedirect != nil {
+		withPolicy := *client
+		withPolicy.CheckRedirect = options.CheckRedirect
+		client = &withPolicy
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download failed: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// ErrDownloadTooLarge is returned when a download exceeds the configured maximum size.
+var ErrDownloadTooLarge = errors.New("download exceeds max size")
+
+// limitDownload wraps r so that reading more than max bytes fails with ErrDownloadTooLarge.
+func limitDownload(r io.Reader, max int64) io.Reader {
+	if max <= 0 {
+		return r
+	}
+	return &limitedDownload{r: r, remaining: max, max: max}
+}
+
+// limitedDownload is the reader returned by limitDownload.
+type limitedDownload struct {
+	r         io.Reader
+	remaining int64
+	max       int64
+}
+
+func (l *limitedDownload) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, fmt.Errorf("%w of %d bytes", ErrDownloadTooLarge, l.max)
+	}
+	// Read one byte past the limit to tell an exact-size body from an oversized one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n - 1, fmt.Errorf("%w of %d bytes", ErrDownloadTooLarge, l.max)
+	}
+	return n, err
+}
+
+// pdfHeaderWindow is how far into a file the %PDF- header may appear.
+const pdfHeaderWindow = 1024
+
+// isPDF reports whether data begins with a PDF header.
+func isPDF(data []byte) bool {
+	if len(data) > pdfHeaderWindow {
+		data = data[:pdfHeaderWindow]
+	}
+	return bytes.Contains(data, []byte("%PDF-"))
+}
+
+// loadFields reads field information from the PDF using pdftk.
+func (f *PDFForm) loadFields() error {
+	blocks, err := f.options.dumpFieldBlocks(context.Background(), f.inputPath)
+	if err != nil {
+		return err
+	}
+
+	for _, block := range blocks {
+		field := parseFieldBlock(block)
+		if field.Name == "" {
+			continue
+		}
+
+		if transform := f.options.FieldNameTransform; transform != nil {
+			pdfName := field.Name
+			field.Name = transform(pdfName)
+			if original, ok := f.pdfNames[field.Name]; ok && original != pdfName {
+				return fmt.Errorf("field name transform maps both %s and %s to %s", original, pdfName, field.Name)
+			}
+			if field.Name != pdfName {
+				if f.pdfNames == nil {
+					f.pdfNames = make(map[string]string)
+				}
+				f.pdfNames[field.Name] = pdfName
+			}
+		}
+
+		if previous, seen := f.fields[field.Name]; !seen {
+			f.order = append(f.order, field.Name)
+		} else {
+			if f.duplicates == nil {
+				f.duplicates = make(map[string]int)
+			}
+			if f.duplicates[field.Name] == 0 {
+				f.duplicates[field.Name] = 1
+			}
+			f.duplicates[field.Name]++
+			f.options.logEvent(slog.LevelWarn, "Duplicate field name in form",
+				"field", field.Name, "type", field.Type, "previous_type", previous.Type)
+		}
+		f.fields[field.Name] = field
+	}
+
+	f.options.overrideFields(f.fields)
+	if f.options.CaseInsensitiveNames {
+		return f.indexFoldedNames()
+	}
+	return nil
+}
+
+// DuplicateFields returns the names that the form defines more than once, with
+// the number of definitions. Only the last definition of each name is kept, so a
+// non-empty result usually indicates a malformed template.
+func (f *PDFForm) DuplicateFields() map[string]int {
+	duplicates := make(map[string]int, len(f.duplicates))
+	for name, count := range f.duplicates {
+		duplicates[name] = count
+	}
+	return duplicates
+}
+
+// Reload re-reads the fields from the input file, e.g. after the template was
+// replaced on disk. Values already set are kept for fields that still exist with
+// the same type; choice values that are no longer valid options are dropped.
+// Names given with RenameField are reapplied.
+func (f *PDFForm) Reload() error {
+	oldFields, oldOrder, oldNames, oldDuplicates := f.fields, f.order, f.pdfNames, f.duplicates
+	f.fields, f.order, f.pdfNames, f.duplicates = make(map[string]Field), nil, nil, nil
+
+	if err := f.loadFields(); err != nil {
+		f.fields, f.order, f.pdfNames, f.duplicates = oldFields, oldOrder, oldNames, oldDuplicates
+		return fmt.Errorf("failed to reload form fields: %w", err)
+	}
+
+	// Reapply renames made with RenameField
+	for name, pdfName := range oldNames {
+		loaded := pdfName
+		if f.options.FieldNameTransform != nil {
+			loaded = f.options.FieldNameTransform(pdfName)
+		}
+		if _, exists := f.fields[loaded]; exists && loaded != name {
+			if err := f.RenameField(loaded, name); err != nil {
+				return fmt.Errorf("failed to reapply field rename: %w", err)
+			}
+		}
+	}
+
+	for name, field := range f.fields {
+		old, exists := oldFields[name]
+		if !exists || old.Value == nil || old.Type != field.Type {
+			continue
+		}
+		if field.Type == Choice && !isValidOption(fmt.Sprint(old.Value), field.Options) {
+			f.options.logEvent(slog.LevelWarn, "Dropping value no longer in field options", "field", name)
+			continue
+		}
+		field.Value = old.Value
+		f.fields[name] = field
+	}
+
+	f.pdfData = nil
+	return nil
+}
+
+// dumpFieldBlocks runs pdftk dump_data_fields and splits its output into per-field blocks.
+func (o Options) dumpFieldBlocks(ctx context.Context, path string) ([]string, error) {
+	o.warnPDFTKVersion()
+	output, err := o.runPDFTK(ctx, path, "dump_data_fields")
+	if err != nil {
+		return nil, err
+	}
+	return splitFieldBlocks(output), nil
+}
+
+// splitFieldBlocks splits pdftk dump_data_fields output into per-field blocks.
+func splitFieldBlocks(dump []byte) []string {
+	return strings.Split(string(dump), "---")
+}
+
+// ParseFields parses the output of pdftk dump_data_fields, e.g. captured by other
+// tooling, into fields keyed by name. Field values are not parsed; use
+// ExtractValues to read the values of a filled PDF.
+func ParseFields(dump []byte) (map[string]Field, error) {
+	fields := make(map[string]Field)
+	for _, block := range splitFieldBlocks(dump) {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		field := parseFieldBlock(block)
+		if field.Name == "" {
+			return nil, fmt.Errorf("invalid pdftk dump: field block without FieldName")
+		}
+		fields[field.Name] = field
+	}
+	return fields, nil
+}
+
+// ExtractValues reads the values entered in an already-filled PDF. Checkbox
+// states are returned as booleans; all other values are returned as strings.
+// Fields without a value are omitted.
+func ExtractValues(path string) (map[string]interface{}, error) {
+	path, err := pdftkPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := newOptions(nil).dumpFieldBlocks(context.Background(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	for _, block := range blocks {
+		field := parseFieldBlock(block)
+		if field.Name == "" {
+			continue
+		}
+		value, ok := parseFieldValue(block)
+		if !ok {
+			continue
+		}
+
+		// Checkboxes have an "Off" state and a single "on" state; radio groups keep their option
+		if field.Type == Boolean && len(field.Options) <= 2 {
+			values[field.Name] = value != "" && value != "Off"
+		} else {
+			values[field.Name] = value
+		}
+	}
+	return values, nil
+}
+
+// parseFieldValue returns the FieldValue entry of a field block from pdftk output.
+func parseFieldValue(block string) (string, bool) {
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		if value, ok := strings.CutPrefix(line, "FieldValue: "); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// parseFieldBlock parses a single field block from pdftk output.
+func parseFieldBlock(block string) Field {
+	lines := strings.Split(block, "\n")
+	field := Field{
+		Options: []string{},
+	}
+	var displays []string
+
+	for _, line := range lines {
+		// Only strip line endings so option values keep their exact spacing
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key, value := parts[0], parts[1]
+		switch key {
+		case "FieldName":
+			field.Name = value
+		case "FieldNameAlt":
+			field.Tooltip = value
+		case "FieldType":
+			field.Type = mapFieldType(value)
+		case "FieldStateOption":
+			field.Options = append(field.Options, value)
+		case "FieldStateOptionDisplay":
+			displays = append(displays, value)
+		case "FieldFlags":
+			if strings.Contains(value, "Required") {
+				field.Required = true
+			}
+		case "FieldMaxLength":
+			field.MaxLength, _ = strconv.Atoi(value)
+		case "FieldValueDefault":
+			field.DefaultValue = value
+		}
+	}
+
+	// Checkbox defaults are states; like ExtractValues, report them as booleans
+	if def, ok := field.DefaultValue.(string); ok && field.Type == Boolean && len(field.Options) <= 2 {
+		field.DefaultValue = def != "" && def != "Off"
+	}
+
+	// Older pdftk versions don't report display labels, so fall back to the export values
+	if len(displays) == len(field.Options) {
+		field.OptionLabels = displays
+	} else {
+		field.OptionLabels = append([]string{}, field.Options...)
+	}
+	return field
+}
+
+// mapFieldType converts pdftk field type to internal FieldType.
+func mapFieldType(pdftkType string) FieldType {
+	switch pdftkType {
+	case "Text":
+		return Text
+	case "Button":
+		return Boolean
+	case "Choice":
+		return Choice
+	default:
+		return Text
+	}
+}
+
+// SetField sets a value for a specific form field with type validation.
+func (f *PDFForm) SetField(name string, value interface{}) error {
+	name = f.resolveName(name)
+	field, exists := f.fields[name]
+	if !exists {
+		if f.options.IgnoreUnknownFields {
+			f.skipUnknownField(name)
+			return nil
+		}
+		return fmt.Errorf("field %s not found in form", name)
+	}
+
+	if f.options.AutoConvert || f.options.ValidationMode == Lenient {
+		converted, err := convertFieldValue(field, value, f.options.LooseOptionMatching)
+		if err != nil {
+			return err
+		}
+		value = converted
+	}
+
+	// Type validation
+	switch field.Type {
+	case Text:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("field %s requires string value", name)
+		}
+	case Boolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("field %s requires boolean value", name)
+		}
+	case Choice:
+		if strVal, ok := value.(string); ok {
+			option, found := matchOption(strVal, field.Options, f.options.LooseOptionMatching)
+			if !found {
+				return fmt.Errorf("invalid option for field %s: %s", name, strVal)
+			}
+			value = option
+		} else {
+			return fmt.Errorf("field %s requires string value from options", name)
+		}
+	}
+
+	if err := f.checkRange(name, value); err != nil {
+		return err
+	}
+
+	field.Value = value
+	if f.options.ValidateOnSet {
+		if err := f.validateField(field); err != nil {
+			return err
+		}
+	}
+
+	f.fields[name] = field
+	f.pdfData = nil
+	f.markDirty(name)
+	f.options.logEvent(slog.LevelDebug, "Field set", "field", name)
+	if f.options.History {
+		f.recordHistory(name, value)
+	}
+	return nil
+}
+
+// ResetToDefault sets a field back to the default value defined by the template.
+// The default state of a checkbox or radio button is set as a boolean: checked
+// unless it is Off or the false state of the field's boolean mapping.
+func (f *PDFForm) ResetToDefault(name string) error {
+	name = f.resolveName(name)
+	field, exists := f.fields[name]
+	if !exists {
+		return fmt.Errorf("field %s not found", name)
+	}
+	if field.DefaultValue == nil {
+		return fmt.Errorf("field %s has no default value", name)
+	}
+
+	value := field.DefaultValue
+	if state, ok := value.(string); ok && field.Type == Boolean {
+		mapping, mapped := f.options.BooleanMappings[name]
+		value = state != "" && state != "Off" && !(mapped && state == mapping.False)
+	}
+	return f.SetField(name, value)
+}
+
+// skipUnknownField logs a value that was ignored because its field is not in the form.
+func (f *PDFForm) skipUnknownField(name string) {
+	f.options.logEvent(slog.LevelInfo, "Skipping unknown field", "field", name)
+}
+
+// SetFields sets multiple field values at once.
+// If some fields cannot be set, the returned error is an *ErrSetFields listing them.
+func (f *PDFForm) SetFields(fields map[string]interface{}) error {
+	setErr := &ErrSetFields{}
+
+	for searchName, value := range fields {
+		if actualName, found := f.FindMatchingField(searchName); found {
+			if err := f.SetField(actualName, value); err != nil {
+				setErr.add(searchName, err)
+			}
+		} else if f.options.IgnoreUnknownFields {
+			f.skipUnknownField(searchName)
+		} else {
+			setErr.UnmatchedFields = append(setErr.UnmatchedFields, searchName)
+		}
+	}
+
+	return setErr.orNil()
+}
+
+// SetFromURLValues fills the form from a submitted HTML form, converting each value
+// to its field's type with ConvertFieldValue. Only the first value of each name is
+// used. Unlike SetFields, names must match exactly. Every failure is returned, in
+// order of field name.
+func (f *PDFForm) SetFromURLValues(v url.Values) []error {
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if _, exists := f.fields[f.resolveName(name)]; !exists {
+			if f.options.IgnoreUnknownFields {
+				f.skipUnknownField(name)
+				continue
+			}
+			errs = append(errs, fmt.Errorf("field %s not found", name))
+			continue
+		}
+
+		value, err := f.ConvertFieldValue(name, v.Get(name))
+		if err == nil {
+			err = f.SetField(name, value)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// SetFieldsMatching sets every field whose name matches pattern to value and
+// returns the number of fields set. The pattern is a glob such as "name_pg*",
+// or a regular expression when enclosed in slashes, e.g. "/^name_pg\d+$/".
+// It stops at the first field that rejects the value.
+func (f *PDFForm) SetFieldsMatching(pattern string, value interface{}) (int, error) {
+	match, err := fieldMatcher(pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, name := range f.order {
+		if _, exists := f.fields[name]; !exists || !match(name) {
+			continue
+		}
+		if err := f.SetField(name, value); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// SetExclusiveGroup checks the selected checkbox and unchecks the other fields in
+// the group, for sets of checkboxes that act like radio buttons. Every field must
+// be a boolean field and selected must be one of them; nothing is set otherwise.
+func (f *PDFForm) SetExclusiveGroup(fields []string, selected string) error {
+	member := false
+	for _, name := range fields {
+		field, exists := f.fields[name]
+		if !exists {
+			return fmt.Errorf("field %s not found", name)
+		}
+		if field.Type != Boolean {
+			return fmt.Errorf("field %s in exclusive group is not a boolean field", name)
+		}
+		member = member || name == selected
+	}
+	if !member {
+		return fmt.Errorf("field %s is not in the exclusive group", selected)
+	}
+
+	// Check the selected box first: unchecking always succeeds, so only that can fail
+	if err := f.SetField(selected, true); err != nil {
+		return err
+	}
+	for _, name := range fields {
+		if name == selected {
+			continue
+		}
+		if err := f.SetField(name, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldMatcher compiles a glob or slash-delimited regular expression into a name matcher.
+func fieldMatcher(pattern string) (func(string) bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid field pattern %s: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid field pattern %s: %w", pattern, err)
+	}
+	return func(name string) bool {
+		matched, _ := path.Match(pattern, name)
+		return matched
+	}, nil
+}
+
+// SetGroup fills a repeating group of numbered fields such as "owner1_name",
+// "owner2_name" row by row. Each record maps the part of the field name after the
+// number (e.g. "name" or "_name") to its value. Rows are assigned to the numbered
+// slots in ascending order; rows beyond the available slots are skipped and reported.
+func (f *PDFForm) SetGroup(prefix string, records []map[string]interface{}) []error {
+	// Collect slot number -> suffix -> field name
+	slots := make(map[int]map[string]string)
+	for name := range f.fields {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := name[len(prefix):]
+		digits := len(rest) - len(strings.TrimLeftFunc(rest, unicode.IsDigit))
+		if digits == 0 {
+			continue
+		}
+		n, err := strconv.Atoi(rest[:digits])
+		if err != nil {
+			continue
+		}
+		if slots[n] == nil {
+			slots[n] = make(map[string]string)
+		}
+		slots[n][strings.TrimLeft(rest[digits:], "_-. ")] = name
+	}
+
+	numbers := make([]int, 0, len(slots))
+	for n := range slots {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	var errs []error
+	for i, record := range records {
+		if i >= len(numbers) {
+			errs = append(errs, fmt.Errorf("group %s: row %d skipped, only %d slots available", prefix, i+1, len(numbers)))
+			continue
+		}
+		slot := slots[numbers[i]]
+		for key, value := range record {
+			name, ok := slot[strings.TrimLeft(key, "_-. ")]
+			if !ok {
+				errs = append(errs, fmt.Errorf("group %s: row %d: field %s not found", prefix, i+1, key))
+				continue
+			}
+			if err := f.SetField(name, value); err != nil {
+				errs = append(errs, fmt.Errorf("group %s: row %d: %w", prefix, i+1, err))
+			}
+		}
+	}
+	return errs
+}
+
+// Validate checks if all required fields have values and if set choice and
+// checkbox values are still valid options of their fields.
+func (f *PDFForm) Validate() error {
+	if f.options.ValidationSummary {
+		return f.validateAll()
+	}
+	for _, field := range f.fields {
+		if f.isRequired(field) && field.Value == nil {
+			f.options.logEvent(slog.LevelWarn, "Validation failed", "field", field.Name, "reason", "required field missing")
+			return fmt.Errorf("required field %s is missing", field.Name)
+		}
+		if err := f.checkRange(field.Name, field.Value); err != nil {
+			f.options.logEvent(slog.LevelWarn, "Validation failed", "field", field.Name, "error", err)
+			return err
+		}
+		if err := f.checkOptions(field); err != nil {
+			f.options.logEvent(slog.LevelWarn, "Validation failed", "field", field.Name, "error", err)
+			return err
+		}
+	}
+	if f.options.ValidationMode == Lenient {
+		return nil
+	}
+	for _, r := range f.rules {
+		if err := r.fn(f); err != nil {
+			f.options.logEvent(slog.LevelWarn, "Validation failed", "rule", r.name, "error", err)
+			return fmt.Errorf("rule %s: %w", r.name, err)
+		}
+	}
+	return nil
+}
+
+// validateAll runs the checks of Validate without stopping at the first failure,
+// logging each failure, and returns them joined into one error.
+func (f *PDFForm) validateAll() error {
+	var errs []error
+	for _, name := range f.order {
+		field, exists := f.fields[name]
+		if exists && f.isRequired(field) && field.Value == nil {
+			f.options.logEvent(slog.LevelWarn, "Validation failed", "field", field.Name, "reason", "required field missing")
+			errs = append(errs, fmt.Errorf("required field %s is missing", field.Name))
+		}
+		if err := f.checkRange(name, field.Value); exists && err != nil {
+			f.options.logEvent(slog.LevelWarn, "Validation failed", "field", field.Name, "error", err)
+			errs = append(errs, err)
+		}
+		if err := f.checkOptions(field); exists && err != nil {
+			f.options.logEvent(slog.LevelWarn, "Validation failed", "field", field.Name, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	if f.options.ValidationMode == Strict {
+		for _, r := range f.rules {
+			if err := r.fn(f); err != nil {
+				f.options.logEvent(slog.LevelWarn, "Validation failed", "rule", r.name, "error", err)
+				errs = append(errs, fmt.Errorf("rule %s: %w", r.name, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		f.options.logEvent(slog.LevelError, "Validation summary", "failures", len(errs))
+	}
+	return errors.Join(errs...)
+}
+
+// SetValidationMode switches the validation mode, e.g. from Lenient during data
+// entry to Strict before final submission.
+func (f *PDFForm) SetValidationMode(mode ValidationMode) {
+	f.options.ValidationMode = mode
+}
+
+// AddRule registers a validation rule spanning multiple fields. Rules run during
+// Validate in Strict mode, in the order they were added, after the required field
+// checks pass.
+func (f *PDFForm) AddRule(name string, fn func(f *PDFForm) error) {
+	f.rules = append(f.rules, rule{name: name, fn: fn})
+}
+
+// SetConditionalRequired makes a field required only when condition returns true.
+// The condition replaces the field's static Required flag and is evaluated against
+// the current values each time the field is validated. Conditions only see values,
+// not whether other fields are required, so rules cannot form cycles; they should
+// not modify the form.
+func (f *PDFForm) SetConditionalRequired(field string, condition func(f *PDFForm) bool) {
+	if _, exists := f.fields[field]; !exists {
+		f.options.logEvent(slog.LevelWarn, "Conditional rule set for unknown field", "field", field)
+	}
+	if f.conditions == nil {
+		f.conditions = make(map[string]func(f *PDFForm) bool)
+	}
+	f.conditions[field] = condition
+}
+
+// isRequired reports whether a field is currently required, taking conditional rules into account.
+func (f *PDFForm) isRequired(field Field) bool {
+	if condition, ok := f.conditions[field.Name]; ok {
+		return condition(f)
+	}
+	return field.Required
+}
+
+// Save writes the filled form to the specified output path.
+func (f *PDFForm) Save(outputPath string) error {
+	return f.SaveContext(context.Background(), outputPath)
+}
+
+// SaveContext writes the filled form to the specified output path, stopping pdftk
+// if ctx is done or the configured command timeout expires. The file is written
+// next to the output path and renamed into place, replacing any existing file
+// atomically; on failure the output path is left untouched.
+func (f *PDFForm) SaveContext(ctx context.Context, outputPath string) error {
+	if err := f.writeFilled(ctx, outputPath); err != nil {
+		return err
 	}
+	f.markClean()
+	return nil
+}
 
-	form := &PDFForm{
-		inputPath: tmpFile.Name(),
-		inputURL:  url,
-		fields:    make(map[string]Field),
-		options:   options,
+// writeFilled writes the filled form to outputPath like SaveContext, without
+// marking the form clean, for callers that use the output as an intermediate file.
+func (f *PDFForm) writeFilled(ctx context.Context, outputPath string) error {
+	outputPath, err := pdftkPath(outputPath)
+	if err != nil {
+		return err
 	}
-
-	if err := form.loadFields(); err != nil {
-		os.Remove(tmpFile.Name())
-		return nil, fmt.Errorf("failed to load form fields: %w", err)
+	if err := ensureOutputDir(outputPath); err != nil {
+		return err
 	}
 
-	// Add cleanup function to the form
-	runtime.SetFinalizer(form, func(f *PDFForm) {
-		if f.inputURL != "" && f.inputPath != "" {
-			os.Remove(f.inputPath)
+	return atomicWrite(outputPath, func(tempPath string) error {
+		if err := f.fill(ctx, tempPath); err != nil {
+			return err
 		}
+		return f.postProcess(ctx, tempPath)
 	})
-
-	return form, nil
 }
 
-// loadFields reads field information from the PDF using pdftk.
-func (f *PDFForm) loadFields() error {
-	cmd := exec.Command("pdftk", f.inputPath, "dump_data_fields")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("pdftk error: %w", err)
+// SetFormatter sets a function that formats a field's value when the form is
+// filled, e.g. to uppercase a license plate. The stored value is unchanged; only
+// the PDF receives the formatted string. It replaces number formatting and
+// checkbox states for the field. Pass nil to remove the formatter.
+func (f *PDFForm) SetFormatter(field string, fn func(interface{}) string) error {
+	if _, exists := f.fields[field]; !exists {
+		return fmt.Errorf("field %s not found", field)
 	}
-
-	blocks := strings.Split(string(output), "---")
-	for _, block := range blocks {
-		field := parseFieldBlock(block)
-		if field.Name != "" {
-			f.fields[field.Name] = field
+	if fn == nil {
+		delete(f.formatters, field)
+	} else {
+		if f.formatters == nil {
+			f.formatters = make(map[string]func(interface{}) string)
 		}
+		f.formatters[field] = fn
 	}
+	f.pdfData = nil
 	return nil
 }
 
-// parseFieldBlock parses a single field block from pdftk output.
-func parseFieldBlock(block string) Field {
-	lines := strings.Split(block, "\n")
-	field := Field{
-		Options: []string{},
-	}
+// formData converts the set field values to the strings written to the PDF.
+func (f *PDFForm) formData() (map[string]string, error) {
+	formData := make(map[string]string)
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+	for name, field := range f.fields {
+		if field.Value == nil {
 			continue
 		}
-
-		parts := strings.SplitN(line, ": ", 2)
-		if len(parts) != 2 {
-			continue
+		if pdfName, ok := f.pdfNames[name]; ok {
+			name = pdfName
 		}
 
-		key, value := parts[0], parts[1]
-		switch key {
-		case "FieldName":
-			field.Name = value
-		case "FieldType":
-			field.Type = mapFieldType(value)
-		case "FieldStateOption":
-			field.Options = append(field.Options, value)
-		case "FieldFlags":
-			if strings.Contains(value, "Required") {
-				field.Required = true
-			}
+		value, err := f.outputValue(field)
+		if err != nil {
+			return nil, err
+		}
+		if kept, _, overflowed := f.splitOverflow(field, value); overflowed {
+			value = kept
 		}
+		formData[name] = value
 	}
-	return field
+
+	return formData, nil
 }
 
-// mapFieldType converts pdftk field type to internal FieldType.
-func mapFieldType(pdftkType string) FieldType {
-	switch pdftkType {
-	case "Text":
-		return Text
-	case "Button":
-		return Boolean
-	case "Choice":
-		return Choice
+// outputValue converts the value of a field to the string written to the PDF.
+func (f *PDFForm) outputValue(field Field) (string, error) {
+	if format, ok := f.formatters[field.Name]; ok {
+		return format(field.Value), nil
+	}
+
+	switch v := field.Value.(type) {
+	case bool:
+		return f.boolExport(field, v)
+	case time.Time:
+		return v.Format(time.RFC3339), nil
 	default:
-		return Text
+		value := fmt.Sprint(v)
+		if field.Type == Text {
+			value = f.options.formatNumber(field.Name, value)
+		}
+		return value, nil
 	}
 }
 
-// SetField sets a value for a specific form field with type validation.
-func (f *PDFForm) SetField(name string, value interface{}) error {
-	field, exists := f.fields[name]
-	if !exists {
-		return fmt.Errorf("field %s not found in form", name)
+// GeneratePDF fills the form into memory. The result is used by Upload until a
+// field value or output setting changes.
+func (f *PDFForm) GeneratePDF() error {
+	data, err := f.fillPDF(context.Background())
+	if err != nil {
+		return err
 	}
+	f.pdfData = data
 
-	// Type validation
-	switch field.Type {
-	case Text:
-		if _, ok := value.(string); !ok {
-			return fmt.Errorf("field %s requires string value", name)
-		}
-	case Boolean:
-		if _, ok := value.(bool); !ok {
-			return fmt.Errorf("field %s requires boolean value", name)
-		}
-	case Choice:
-		if strVal, ok := value.(string); ok {
-			if !isValidOption(strVal, field.Options) {
-				return fmt.Errorf("invalid option for field %s: %s", name, strVal)
-			}
-		} else {
-			return fmt.Errorf("field %s requires string value from options", name)
+	f.options.logEvent(slog.LevelInfo, "PDF generated successfully", "size", len(data))
+	return nil
+}
+
+// WriteTo writes the filled PDF to w, using the PDF rendered by GeneratePDF if available.
+func (f *PDFForm) WriteTo(w io.Writer) (int64, error) {
+	data := f.pdfData
+	if data == nil {
+		var err error
+		if data, err = f.fillPDF(context.Background()); err != nil {
+			return 0, err
 		}
 	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
 
-	field.Value = value
-	f.fields[name] = field
+// Bytes returns the filled PDF without writing it to a destination file.
+func (f *PDFForm) Bytes() ([]byte, error) {
+	return f.fillPDF(context.Background())
+}
 
-	if f.options.ValidateOnSet {
-		return f.validateField(field)
+// volatilePDFEntries matches PDF entries that pdftk regenerates on every run.
+var volatilePDFEntries = regexp.MustCompile(`/(ID\s*\[[^\]]*\]|CreationDate\s*\([^)]*\)|ModDate\s*\([^)]*\))`)
+
+// Fingerprint returns the hex-encoded SHA-256 hash of the filled PDF. The document
+// ID and creation/modification dates that pdftk writes on each run are excluded
+// from the hash, so identical templates and values normally produce the same
+// fingerprint. Determinism is best effort: it is not guaranteed across pdftk
+// versions or if the output stores these entries in compressed object streams.
+func (f *PDFForm) Fingerprint() (string, error) {
+	data, err := f.fillPDF(context.Background())
+	if err != nil {
+		return "", err
 	}
-	return nil
+	sum := sha256.Sum256(volatilePDFEntries.ReplaceAll(data, nil))
+	return hex.EncodeToString(sum[:]), nil
 }
 
-// SetFields sets multiple field values at once.
-func (f *PDFForm) SetFields(fields map[string]interface{}) error {
-	var errors []string
+// fillPDF fills the form into a temporary file and returns its contents.
+func (f *PDFForm) fillPDF(ctx context.Context) ([]byte, error) {
+	// Create a temporary file for pdftk (it requires file paths)
+	tmpFile, err := os.CreateTemp("", "pdf-output-*.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempOutput := tmpFile.Name()
+	tmpFile.Close()
+	defer f.options.removeTemp(tempOutput)
 
-	for searchName, value := range fields {
-		if actualName, found := f.FindMatchingField(searchName); found {
-			if err := f.SetField(actualName, value); err != nil {
-				errors = append(errors, fmt.Sprintf("field '%s': %v", searchName, err))
-			}
-		} else {
-			errors = append(errors, fmt.Sprintf("field '%s' not found", searchName))
-		}
+	if err := f.fill(ctx, tempOutput); err != nil {
+		return nil, err
+	}
+	if err := f.postProcess(ctx, tempOutput); err != nil {
+		return nil, err
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to set some fields: %s", strings.Join(errors, "; "))
+	// Read the temporary file
+	data, err := os.ReadFile(tempOutput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read filled PDF: %w", err)
 	}
 
-	return nil
+	return data, nil
 }
 
-// Validate checks if all required fields have values.
-func (f *PDFForm) Validate() error {
-	for _, field := range f.fields {
-		if field.Required && field.Value == nil {
-			return fmt.Errorf("required field %s is missing", field.Name)
-		}
+// matchOption returns the option matching value. With loose matching, case and
+// leading, trailing or repeated whitespace are ignored, and the option is returned
+// exactly as the form defines it.
+func matchOption(value string, options []string, loose bool) (string, bool) {
+	if isValidOption(value, options) {
+		return value, true
+	}
+	if !loose {
+		return "", false
 	}
-	return nil
-}
-
-// Save writes the filled form to the specified output path.
-func (f *PDFForm) Save(outputPath string) error {
-	formData := make(fillpdf.Form)
-
-	for name, field := range f.fields {
-		if field.Value == nil {
-			continue
-		}
 
-		switch v := field.Value.(type) {
-		case bool:
-			if v {
-				formData[name] = "On"
-			} else {
-				formData[name] = "Off"
-			}
-		case time.Time:
-			formData[name] = v.Format(time.RFC3339)
-		default:
-			formData[name] = fmt.Sprint(v)
+	normalized := normalizeOption(value)
+	for _, opt := range options {
+		if normalizeOption(opt) == normalized {
+			return opt, true
 		}
 	}
+	return "", false
+}
 
-	if err := fillpdf.Fill(formData, f.inputPath, outputPath); err != nil {
-		return fmt.Errorf("fillpdf error: %w", err)
-	}
-	return nil
+// normalizeOption lowercases an option and collapses its whitespace.
+func normalizeOption(option string) string {
+	return strings.ToLower(strings.Join(strings.Fields(option), " "))
 }
 
 // isValidOption checks if a value is in the list of allowed options.
@@ -330,61 +1531,169 @@ func isValidOption(value string, options []string) bool {
 
 // validateField checks if a field meets validation requirements.
 func (f *PDFForm) validateField(field Field) error {
-	if field.Required && field.Value == nil {
+	if f.isRequired(field) && field.Value == nil {
 		return fmt.Errorf("required field %s is not set", field.Name)
 	}
+
+	return f.checkOptions(field)
+}
+
+// checkOptions reports a choice value that is not one of the field's options, or
+// a checked state the checkbox does not define. Values are checked when set, but
+// may become invalid when options are overridden or the template is reloaded.
+func (f *PDFForm) checkOptions(field Field) error {
+	switch v := field.Value.(type) {
+	case string:
+		if field.Type != Choice {
+			return nil
+		}
+		if _, found := matchOption(v, field.Options, f.options.LooseOptionMatching); !found {
+			return fmt.Errorf("invalid option for field %s: %s", field.Name, v)
+		}
+	case bool:
+		// Off is always valid, but a checkbox may name its checked state anything, e.g. "1"
+		if v && field.Type == Boolean && len(field.Options) > 0 {
+			state, err := f.boolExport(field, v)
+			if err != nil {
+				return err
+			}
+			if !isValidOption(state, field.Options) {
+				return fmt.Errorf("field %s does not support state %s; valid states: %s",
+					field.Name, state, strings.Join(field.Options, ", "))
+			}
+		}
+	}
 	return nil
 }
 
+// boolState returns the checkbox state written to the PDF for a boolean value.
+func boolState(checked bool) string {
+	if checked {
+		return "On"
+	}
+	return "Off"
+}
+
 // Upload generates the filled PDF and uploads it using the configured uploader service.
 func (f *PDFForm) Upload(ctx context.Context, config types.UploadConfig) (*types.UploadResponse, error) {
 	if f.options.Uploader == nil {
 		return nil, fmt.Errorf("uploader service not configured")
 	}
 
-	// Convert form data to fillpdf.Form
-	formData := make(fillpdf.Form)
-	for name, field := range f.fields {
-		if field.Value == nil {
-			continue
+	// Use the PDF rendered by GeneratePDF if available
+	data := f.pdfData
+	if data == nil {
+		var err error
+		if data, err = f.fillPDF(ctx); err != nil {
+			return nil, err
 		}
+	}
 
-		switch v := field.Value.(type) {
-		case bool:
-			if v {
-				formData[name] = "On"
-			} else {
-				formData[name] = "Off"
-			}
-		case time.Time:
-			formData[name] = v.Format(time.RFC3339)
-		default:
-			formData[name] = fmt.Sprint(v)
-		}
+	// Upload the filled PDF
+	start := time.Now()
+	response, err := f.options.Uploader.Upload(ctx, data, config)
+	if err != nil {
+		f.options.logEvent(slog.LevelError, "Upload failed", "file", config.FileName, "status", "failed", "duration", time.Since(start), "error", err)
+		return nil, fmt.Errorf("failed to upload PDF: %w", err)
 	}
+	f.options.logEvent(slog.LevelInfo, "Upload succeeded", "file", config.FileName, "status", "success", "duration", time.Since(start), "size", len(data))
+	f.markClean()
+
+	return response, nil
+}
 
-	// Create a temporary file for fillpdf (it requires file paths)
-	tempOutput := "temp_output.pdf"
-	if err := fillpdf.Fill(formData, f.inputPath, tempOutput); err != nil {
-		return nil, fmt.Errorf("failed to fill PDF: %w", err)
+// UploadStream fills the PDF and uploads it while reading the filled file, so it
+// is never held in memory. It falls back to Upload when the form has a rendered
+// PDF or the uploader does not implement service.StreamUploader. Streamed bodies
+// cannot be replayed; use Upload when the upload may need to be resent.
+func (f *PDFForm) UploadStream(ctx context.Context, config types.UploadConfig) (*types.UploadResponse, error) {
+	streamer, ok := f.options.Uploader.(service.StreamUploader)
+	if !ok || f.pdfData != nil {
+		return f.Upload(ctx, config)
 	}
 
-	// Read the temporary file
-	data, err := os.ReadFile(tempOutput)
+	tmpFile, err := os.CreateTemp("", "pdf-output-*.pdf")
 	if err != nil {
-		os.Remove(tempOutput) // Clean up
-		return nil, fmt.Errorf("failed to read filled PDF: %w", err)
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempOutput := tmpFile.Name()
+	tmpFile.Close()
+	defer f.options.removeTemp(tempOutput)
+
+	if err := f.fill(ctx, tempOutput); err != nil {
+		return nil, err
+	}
+	if err := f.postProcess(ctx, tempOutput); err != nil {
+		return nil, err
 	}
 
-	// Clean up the temporary file
-	os.Remove(tempOutput)
+	file, err := os.Open(tempOutput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open filled PDF: %w", err)
+	}
+	defer file.Close()
+
+	start := time.Now()
+	response, err := streamer.UploadStream(ctx, file, config)
+	if err != nil {
+		f.options.logEvent(slog.LevelError, "Upload failed", "file", config.FileName, "status", "failed", "duration", time.Since(start), "error", err)
+		return nil, fmt.Errorf("failed to upload PDF: %w", err)
+	}
+	f.options.logEvent(slog.LevelInfo, "Upload succeeded", "file", config.FileName, "status", "success", "duration", time.Since(start))
+	f.markClean()
+
+	return response, nil
+}
+
+// UploadAndVerify uploads the filled PDF, then downloads it back from the returned
+// download URI and checks that the stored file matches what was sent.
+func (f *PDFForm) UploadAndVerify(ctx context.Context, config types.UploadConfig) (*types.UploadResponse, error) {
+	if f.options.Uploader == nil {
+		return nil, fmt.Errorf("uploader service not configured")
+	}
+
+	data, err := f.fillPDF(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// Upload the filled PDF
 	response, err := f.options.Uploader.Upload(ctx, data, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload PDF: %w", err)
 	}
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, response.FileDownloadUri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verification request: %w", err)
+	}
+
+	resp, err := f.options.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download uploaded PDF: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download uploaded PDF: status %d", resp.StatusCode)
+	}
+
+	stored, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded PDF: %w", err)
+	}
+
+	sentHash := sha256.Sum256(data)
+	storedHash := sha256.Sum256(stored)
+	if len(stored) != len(data) || sentHash != storedHash {
+		return response, &service.ErrVerification{
+			ExpectedSize: int64(len(data)),
+			ActualSize:   int64(len(stored)),
+			ExpectedHash: hex.EncodeToString(sentHash[:]),
+			ActualHash:   hex.EncodeToString(storedHash[:]),
+		}
+	}
+	f.markClean()
+
 	return response, nil
 }
 
@@ -398,6 +1707,115 @@ func (f *PDFForm) GetFields() map[string]Field {
 	return fields
 }
 
+// Values returns the values of the fields that have been set, keyed by field name.
+func (f *PDFForm) Values() map[string]interface{} {
+	values := make(map[string]interface{})
+	for name, field := range f.fields {
+		if field.Value != nil {
+			values[name] = field.Value
+		}
+	}
+	return values
+}
+
+// RenameField changes the name a field is known by in this form. The new name is
+// used by every method of the form, but pdftk cannot rename fields, so the saved PDF
+// keeps the template's field name and only the data written to it is remapped.
+func (f *PDFForm) RenameField(oldName, newName string) error {
+	field, exists := f.fields[oldName]
+	if !exists {
+		return fmt.Errorf("field %s not found in form", oldName)
+	}
+	if _, exists := f.fields[newName]; exists {
+		return fmt.Errorf("field %s already exists in form", newName)
+	}
+	if f.folded != nil {
+		if other, ok := f.folded[strings.ToLower(newName)]; ok && other != oldName {
+			return fmt.Errorf("field %s differs only in case from %s", newName, other)
+		}
+		delete(f.folded, strings.ToLower(oldName))
+		f.folded[strings.ToLower(newName)] = newName
+	}
+
+	if f.pdfNames == nil {
+		f.pdfNames = make(map[string]string)
+	}
+	pdfName := oldName
+	if original, ok := f.pdfNames[oldName]; ok {
+		pdfName = original
+		delete(f.pdfNames, oldName)
+	}
+	f.pdfNames[newName] = pdfName
+
+	field.Name = newName
+	delete(f.fields, oldName)
+	f.fields[newName] = field
+
+	for i, name := range f.order {
+		if name == oldName {
+			f.order[i] = newName
+		}
+	}
+	if condition, ok := f.conditions[oldName]; ok {
+		delete(f.conditions, oldName)
+		f.conditions[newName] = condition
+	}
+	if format, ok := f.formatters[oldName]; ok {
+		delete(f.formatters, oldName)
+		f.formatters[newName] = format
+	}
+	if r, ok := f.ranges[oldName]; ok {
+		delete(f.ranges, oldName)
+		f.ranges[newName] = r
+	}
+	if entries, ok := f.history[oldName]; ok {
+		delete(f.history, oldName)
+		f.history[newName] = entries
+	}
+	if f.dirty[oldName] {
+		delete(f.dirty, oldName)
+		f.dirty[newName] = true
+	}
+	return nil
+}
+
+// DiffValues compares the field values of two forms and returns, for each field
+// present in either form whose values differ, the value in f followed by the value
+// in other. Call it on the previous version with the current one to get old/new pairs.
+func (f *PDFForm) DiffValues(other *PDFForm) map[string][2]interface{} {
+	diff := make(map[string][2]interface{})
+	for name, field := range f.fields {
+		var otherValue interface{}
+		if otherField, ok := other.fields[name]; ok {
+			otherValue = otherField.Value
+		}
+		if !reflect.DeepEqual(field.Value, otherValue) {
+			diff[name] = [2]interface{}{field.Value, otherValue}
+		}
+	}
+	for name, otherField := range other.fields {
+		if _, ok := f.fields[name]; !ok && otherField.Value != nil {
+			diff[name] = [2]interface{}{nil, otherField.Value}
+		}
+	}
+	return diff
+}
+
+// FieldsInOrder returns all fields in the order pdftk reported them,
+// which roughly follows their position in the document.
+func (f *PDFForm) FieldsInOrder() []Field {
+	fields := make([]Field, 0, len(f.order))
+	for _, name := range f.order {
+		fields = append(fields, f.fields[name])
+	}
+	return fields
+}
+
+// Summary returns field counts for the PDF form.
+func (f *PDFForm) Summary() FormSummary {
+	return summarize(f.fields, f.isRequired)
+}
+
 // PrintFields prints all fields and their properties to the configured logger.
 func (f *PDFForm) PrintFields() {
 	if f.options.Logger == nil {
@@ -438,6 +1856,7 @@ type PDFProcessorConfig struct {
 	// Optional configurations
 	ValidateOnSet bool
 	Logger        *log.Logger
+	HTTPClient    *http.Client // Client for downloads and uploads, e.g. for proxy configuration
 }
 
 // NewPDFProcessor creates a new PDF processor with the given configuration
@@ -445,13 +1864,17 @@ func NewPDFProcessor(config PDFProcessorConfig) (*PDFForm, error) {
 	uploader := service.NewUploader(service.Config{
 		UploadBaseURL: config.UploadBaseURL,
 		BearerToken:   config.BearerToken,
+		HTTPClient:    config.HTTPClient,
 	})
 
-	options := Options{
-		ValidateOnSet: config.ValidateOnSet,
-		Logger:        config.Logger,
-		Uploader:      uploader,
+	// Start from the defaults so limits such as MaxDownloadSize apply here too
+	options := newOptions(nil)
+	options.ValidateOnSet = config.ValidateOnSet
+	if config.Logger != nil {
+		options.Logger = config.Logger
 	}
+	options.Uploader = uploader
+	options.HTTPClient = config.HTTPClient
 
 	return &PDFForm{
 		options: options,
@@ -510,10 +1933,16 @@ func (f *PDFForm) NormalizeFieldName(name string) string {
 
 // ConvertFieldValue converts a value to the appropriate type based on the field type
 func (f *PDFForm) ConvertFieldValue(name string, value interface{}) (interface{}, error) {
-	field, exists := f.fields[name]
+	field, exists := f.fields[f.resolveName(name)]
 	if !exists {
 		return nil, fmt.Errorf("field %s not found", name)
 	}
+	return convertFieldValue(field, value, f.options.LooseOptionMatching)
+}
+
+// convertFieldValue converts a value to the appropriate type for a field
+func convertFieldValue(field Field, value interface{}, looseOptions bool) (interface{}, error) {
+	name := field.Name
 
 	switch field.Type {
 	case Boolean:
@@ -541,10 +1970,11 @@ func (f *PDFForm) ConvertFieldValue(name string, value interface{}) (interface{}
 		}
 	case Choice:
 		strVal := fmt.Sprintf("%v", value)
-		if !isValidOption(strVal, field.Options) {
+		option, found := matchOption(strVal, field.Options, looseOptions)
+		if !found {
 			return nil, fmt.Errorf("invalid option for field %s: %s", name, strVal)
 		}
-		return strVal, nil
+		return option, nil
 	default:
 		return fmt.Sprintf("%v", value), nil
 	}
@@ -552,6 +1982,11 @@ func (f *PDFForm) ConvertFieldValue(name string, value interface{}) (interface{}
 
 // FindMatchingField attempts to find a matching field name using normalized comparison
 func (f *PDFForm) FindMatchingField(searchName string) (string, bool) {
+	name := f.resolveName(searchName)
+	if _, exists := f.fields[name]; exists {
+		return name, true
+	}
+
 	normalized := f.NormalizeFieldName(searchName)
 
 	// Try exact match first (case-insensitive)
@@ -0,0 +1,438 @@
+package pdfprocessor
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testDump is pdftk dump_data_fields output for the form loaded by newTestForm.
+const testDump = `---
+FieldType: Text
+FieldName: name
+FieldFlags: Required
+FieldNameAlt: Full name
+---
+FieldType: Text
+FieldName: amount
+---
+FieldType: Button
+FieldName: agree
+FieldStateOption: Off
+FieldStateOption: Yes
+---
+FieldType: Choice
+FieldName: country
+FieldStateOption: MW
+FieldStateOption: ZA
+FieldValueDefault: MW
+---
+FieldType: Text
+FieldName: notes
+FieldMaxLength: 20
+`
+
+// newTestForm loads a form the way NewForm does, with a fake pdftk reporting the
+// fields in dump.
+func newTestForm(t *testing.T, dump string, opts ...Option) *PDFForm {
+	t.Helper()
+	dir := installPDFTK(t, `case "$*" in
+*--version*) echo "pdftk port to java 3.3.3" ;;
+*dump_data_fields*) cat "$(dirname "$0")/dump.txt" ;;
+esac
+`)
+	if err := os.WriteFile(filepath.Join(dir, "dump.txt"), []byte(dump), 0644); err != nil {
+		t.Fatal(err)
+	}
+	template := filepath.Join(dir, "form.pdf")
+	if err := os.WriteFile(template, []byte("%PDF-1.4"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := NewForm(template, append([]Option{WithLogger(nil)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewForm() error = %v", err)
+	}
+	return form
+}
+
+func TestSetExclusiveGroupLeavesGroupOnFailure(t *testing.T) {
+	f := &PDFForm{
+		fields: map[string]Field{
+			"a": {Name: "a", Type: Boolean, Value: true, Options: []string{"On", "Off"}},
+			"b": {Name: "b", Type: Boolean, Value: false, Options: []string{"On", "Off"}},
+		},
+		order: []string{"a", "b"},
+		options: Options{
+			ValidateOnSet:   true,
+			BooleanMappings: map[string]BooleanMapping{"b": {True: "Yes", False: "Off"}},
+		},
+	}
+
+	if err := f.SetExclusiveGroup([]string{"a", "b"}, "b"); err == nil {
+		t.Fatal("SetExclusiveGroup() succeeded with an unsupported checked state")
+	}
+	if got := f.fields["a"].Value; got != true {
+		t.Errorf("a = %v after failed SetExclusiveGroup, want true", got)
+	}
+	if got := f.fields["b"].Value; got != false {
+		t.Errorf("b = %v after failed SetExclusiveGroup, want false", got)
+	}
+	if f.IsDirty() {
+		t.Errorf("form is dirty after failed SetExclusiveGroup: %v", f.DirtyFields())
+	}
+
+	if err := f.SetExclusiveGroup([]string{"a", "b"}, "a"); err != nil {
+		t.Fatalf("SetExclusiveGroup() error = %v", err)
+	}
+	if f.fields["a"].Value != true || f.fields["b"].Value != false {
+		t.Errorf("group = %v, %v; want true, false", f.fields["a"].Value, f.fields["b"].Value)
+	}
+}
+
+func TestSetFieldValidateOnSetRejectsWithoutStoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		mapping map[string]BooleanMapping
+		value   interface{}
+	}{
+		{
+			name:    "unsupported checkbox state",
+			field:   Field{Name: "agree", Type: Boolean, Value: false, Options: []string{"On", "Off"}},
+			mapping: map[string]BooleanMapping{"agree": {True: "Yes", False: "Off"}},
+			value:   true,
+		},
+		{
+			name:  "choice not in options",
+			field: Field{Name: "color", Type: Choice, Value: "Red", Options: []string{"Red", "Blue"}},
+			value: "Green",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &PDFForm{
+				fields:  map[string]Field{tt.field.Name: tt.field},
+				order:   []string{tt.field.Name},
+				pdfData: []byte("%PDF"),
+				options: Options{ValidateOnSet: true, History: true, BooleanMappings: tt.mapping},
+			}
+
+			if err := f.SetField(tt.field.Name, tt.value); err == nil {
+				t.Fatalf("SetField(%v) succeeded", tt.value)
+			}
+			if got := f.fields[tt.field.Name].Value; got != tt.field.Value {
+				t.Errorf("value = %v after rejected SetField, want %v", got, tt.field.Value)
+			}
+			if f.IsDirty() {
+				t.Errorf("form is dirty after rejected SetField: %v", f.DirtyFields())
+			}
+			if h := f.FieldHistory(tt.field.Name); h != nil {
+				t.Errorf("history = %v after rejected SetField, want none", h)
+			}
+			if f.pdfData == nil {
+				t.Error("rendered PDF was cleared by a rejected SetField")
+			}
+		})
+	}
+}
+
+func TestNewPDFProcessorDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PDFProcessorConfig
+	}{
+		{name: "empty config", config: PDFProcessorConfig{}},
+		{name: "validation", config: PDFProcessorConfig{ValidateOnSet: true, UploadBaseURL: "https://example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form, err := NewPDFProcessor(tt.config)
+			if err != nil {
+				t.Fatalf("NewPDFProcessor() error = %v", err)
+			}
+			o := form.options
+			if o.MaxDownloadSize != defaultMaxDownloadSize {
+				t.Errorf("MaxDownloadSize = %d, want %d", o.MaxDownloadSize, defaultMaxDownloadSize)
+			}
+			if o.CommandAttempts != defaultCommandAttempts {
+				t.Errorf("CommandAttempts = %d, want %d", o.CommandAttempts, defaultCommandAttempts)
+			}
+			if o.Logger == nil {
+				t.Error("Logger is nil")
+			}
+			if o.ValidateOnSet != tt.config.ValidateOnSet {
+				t.Errorf("ValidateOnSet = %v, want %v", o.ValidateOnSet, tt.config.ValidateOnSet)
+			}
+			if o.Uploader == nil {
+				t.Error("Uploader is nil")
+			}
+		})
+	}
+}
+
+func TestResetToDefault(t *testing.T) {
+	radio := []string{"Choice1", "Choice2", "Off"}
+	tests := []struct {
+		name    string
+		field   Field
+		mapping map[string]BooleanMapping
+		lookup  string
+		want    interface{}
+	}{
+		{
+			name:   "text",
+			field:  Field{Name: "City", Type: Text, Value: "Paris", DefaultValue: "Lilongwe"},
+			lookup: "City",
+			want:   "Lilongwe",
+		},
+		{
+			name:   "text by folded name",
+			field:  Field{Name: "City", Type: Text, Value: "Paris", DefaultValue: "Lilongwe"},
+			lookup: "city",
+			want:   "Lilongwe",
+		},
+		{
+			name:   "checkbox parsed as boolean",
+			field:  Field{Name: "Agree", Type: Boolean, Value: false, Options: []string{"Yes", "Off"}, DefaultValue: true},
+			lookup: "Agree",
+			want:   true,
+		},
+		{
+			name:    "radio state",
+			field:   Field{Name: "Plan", Type: Boolean, Value: false, Options: radio, DefaultValue: "Choice2"},
+			mapping: map[string]BooleanMapping{"Plan": {True: "Choice2", False: "Off"}},
+			lookup:  "Plan",
+			want:    true,
+		},
+		{
+			name:   "radio off",
+			field:  Field{Name: "Plan", Type: Boolean, Value: true, Options: radio, DefaultValue: "Off"},
+			lookup: "Plan",
+			want:   false,
+		},
+		{
+			name:    "mapped false state",
+			field:   Field{Name: "Plan", Type: Boolean, Value: true, Options: radio, DefaultValue: "Choice1"},
+			mapping: map[string]BooleanMapping{"Plan": {True: "Choice2", False: "Choice1"}},
+			lookup:  "Plan",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &PDFForm{
+				fields:  map[string]Field{tt.field.Name: tt.field},
+				order:   []string{tt.field.Name},
+				options: Options{BooleanMappings: tt.mapping},
+			}
+			if err := f.indexFoldedNames(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := f.ResetToDefault(tt.lookup); err != nil {
+				t.Fatalf("ResetToDefault(%q) error = %v", tt.lookup, err)
+			}
+			if got := f.fields[tt.field.Name].Value; got != tt.want {
+				t.Errorf("value = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		old   map[string]interface{}
+		new   map[string]interface{}
+		other string // dump of the other form, testDump if empty
+		want  map[string][2]interface{}
+	}{
+		{
+			name: "identical",
+			old:  map[string]interface{}{"name": "Ada", "agree": true},
+			new:  map[string]interface{}{"name": "Ada", "agree": true},
+			want: map[string][2]interface{}{},
+		},
+		{
+			name: "changed, set and cleared values",
+			old:  map[string]interface{}{"name": "Ada", "agree": true},
+			new:  map[string]interface{}{"name": "Grace", "amount": "10"},
+			want: map[string][2]interface{}{
+				"name":   {"Ada", "Grace"},
+				"amount": {nil, "10"},
+				"agree":  {true, nil},
+			},
+		},
+		{
+			name:  "field only in the other form",
+			old:   map[string]interface{}{"name": "Ada"},
+			new:   map[string]interface{}{"name": "Ada", "extra": "x"},
+			other: testDump + "---\nFieldType: Text\nFieldName: extra\n",
+			want:  map[string][2]interface{}{"extra": {nil, "x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldForm := newTestForm(t, testDump)
+			otherDump := tt.other
+			if otherDump == "" {
+				otherDump = testDump
+			}
+			newForm := newTestForm(t, otherDump)
+			if err := oldForm.SetFields(tt.old); err != nil {
+				t.Fatal(err)
+			}
+			if err := newForm.SetFields(tt.new); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := oldForm.DiffValues(newForm); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameField(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		renames  [][2]string
+		wantErr  bool
+		wantName string // Name the template field "name" is known by afterwards
+	}{
+		{name: "rename", renames: [][2]string{{"name", "full_name"}}, wantName: "full_name"},
+		{name: "rename twice", renames: [][2]string{{"name", "full_name"}, {"full_name", "applicant"}}, wantName: "applicant"},
+		{name: "missing field", renames: [][2]string{{"phone", "mobile"}}, wantErr: true, wantName: "name"},
+		{name: "existing name", renames: [][2]string{{"name", "notes"}}, wantErr: true, wantName: "name"},
+		{
+			name:     "differs only in case",
+			opts:     []Option{WithCaseInsensitiveFields()},
+			renames:  [][2]string{{"name", "NOTES"}},
+			wantErr:  true,
+			wantName: "name",
+		},
+		{
+			name:     "case-insensitive lookup of new name",
+			opts:     []Option{WithCaseInsensitiveFields()},
+			renames:  [][2]string{{"name", "FullName"}},
+			wantName: "fullname",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestForm(t, testDump, append([]Option{WithHistory()}, tt.opts...)...)
+			if err := f.SetField("name", "Ada"); err != nil {
+				t.Fatal(err)
+			}
+
+			var err error
+			for _, rename := range tt.renames {
+				if err = f.RenameField(rename[0], rename[1]); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenameField() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			field, ok := f.GetField(tt.wantName)
+			if !ok || field.Value != "Ada" {
+				t.Fatalf("GetField(%s) = %+v, %v; want the renamed field", tt.wantName, field, ok)
+			}
+			if err := f.SetField(tt.wantName, "Grace"); err != nil {
+				t.Errorf("SetField(%s) error = %v", tt.wantName, err)
+			}
+			if len(f.FieldHistory(field.Name)) != 2 {
+				t.Errorf("history of %s = %v, want both values", field.Name, f.FieldHistory(field.Name))
+			}
+			if !reflect.DeepEqual(f.DirtyFields(), []string{field.Name}) {
+				t.Errorf("DirtyFields() = %v, want [%s]", f.DirtyFields(), field.Name)
+			}
+
+			// The PDF keeps the template's field name
+			data, err := f.formData()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data["name"] != "Grace" || len(data) != 1 {
+				t.Errorf("formData() = %v, want the value under the template name", data)
+			}
+		})
+	}
+}
+
+func TestSetFromURLValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		query      string
+		wantValues map[string]interface{}
+		wantErrs   int
+	}{
+		{
+			name:       "converts values",
+			query:      "name=Ada&amount=42&agree=on&country=ZA",
+			wantValues: map[string]interface{}{"name": "Ada", "amount": "42", "agree": true, "country": "ZA"},
+		},
+		{
+			name:       "first value used",
+			query:      "name=Ada&name=Grace",
+			wantValues: map[string]interface{}{"name": "Ada"},
+		},
+		{
+			name:       "unchecked box",
+			query:      "agree=off",
+			wantValues: map[string]interface{}{"agree": false},
+		},
+		{
+			name:       "errors for each failure",
+			query:      "name=Ada&agree=maybe&country=US&phone=555",
+			wantValues: map[string]interface{}{"name": "Ada"},
+			wantErrs:   3,
+		},
+		{
+			name:       "unknown names ignored",
+			opts:       []Option{WithIgnoreUnknownFields()},
+			query:      "name=Ada&phone=555",
+			wantValues: map[string]interface{}{"name": "Ada"},
+		},
+		{
+			name:       "normalized names not matched",
+			query:      "Full-Name=Ada&NAME=Ada",
+			wantValues: map[string]interface{}{},
+			wantErrs:   2,
+		},
+		{
+			name:       "case-insensitive names",
+			opts:       []Option{WithCaseInsensitiveFields()},
+			query:      "NAME=Ada&Agree=1",
+			wantValues: map[string]interface{}{"name": "Ada", "agree": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestForm(t, testDump, tt.opts...)
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if errs := f.SetFromURLValues(values); len(errs) != tt.wantErrs {
+				t.Errorf("SetFromURLValues() errors = %v, want %d", errs, tt.wantErrs)
+			}
+			if got := f.Values(); !reflect.DeepEqual(got, tt.wantValues) {
+				t.Errorf("Values() = %v, want %v", got, tt.wantValues)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package pdfprocessor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// numericRange is the inclusive range of numbers allowed in a text field.
+type numericRange struct {
+	min, max float64
+}
+
+// SetNumericRange requires a text field, such as a year or odometer reading, to
+// hold a number between min and max inclusive. SetField rejects other values and
+// Validate reports them.
+func (f *PDFForm) SetNumericRange(field string, min, max float64) error {
+	existing, exists := f.fields[field]
+	if !exists {
+		return fmt.Errorf("field %s not found", field)
+	}
+	if existing.Type != Text {
+		return fmt.Errorf("field %s is not a text field", field)
+	}
+	if min > max {
+		return fmt.Errorf("invalid range for field %s: min %v is greater than max %v", field, min, max)
+	}
+
+	if f.ranges == nil {
+		f.ranges = make(map[string]numericRange)
+	}
+	f.ranges[field] = numericRange{min: min, max: max}
+	return nil
+}
+
+// checkRange checks a value against the field's numeric range, if it has one.
+func (f *PDFForm) checkRange(name string, value interface{}) error {
+	r, ok := f.ranges[name]
+	if !ok || value == nil {
+		return nil
+	}
+
+	text := strings.TrimSpace(fmt.Sprint(value))
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(n) {
+		return fmt.Errorf("field %s requires a number, got %q", name, text)
+	}
+	if n < r.min || n > r.max {
+		return fmt.Errorf("field %s must be between %v and %v, got %s", name, r.min, r.max, text)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package pdfprocessor
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Codes reported in FieldError.Code
+const (
+	CodeRequired      = "required"       // A required field has no value
+	CodeTypeMismatch  = "type_mismatch"  // A value does not match the field type
+	CodeInvalidOption = "invalid_option" // A choice value or checkbox state is not one of the field options
+	CodeRuleFailed    = "rule_failed"    // A rule added with AddRule failed
+	CodeOutOfRange    = "out_of_range"   // A value is not a number within the range set with SetNumericRange
+	CodeUnknownField  = "unknown_field"  // A value was given for a field the form does not have
+)
+
+// FieldError describes a single validation problem in a form for API responses.
+// For rule failures, Field holds the rule name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationReport checks every field and rule and returns all problems found,
+// in document order followed by rule failures. It applies the same checks as
+// Validate but does not stop at the first problem.
+func (f *PDFForm) ValidationReport() []FieldError {
+	var report []FieldError
+
+	for _, name := range f.order {
+		field, exists := f.fields[name]
+		if !exists {
+			continue
+		}
+
+		if field.Value == nil {
+			if f.isRequired(field) {
+				report = append(report, FieldError{
+					Field:   name,
+					Code:    CodeRequired,
+					Message: fmt.Sprintf("required field %s is missing", name),
+				})
+			}
+			continue
+		}
+
+		if fieldErr, ok := f.checkValue(field); !ok {
+			report = append(report, fieldErr)
+		} else if err := f.checkRange(name, field.Value); err != nil {
+			report = append(report, FieldError{
+				Field:   name,
+				Code:    CodeOutOfRange,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	if f.options.ValidationMode == Lenient {
+		return report
+	}
+	for _, r := range f.rules {
+		if err := r.fn(f); err != nil {
+			report = append(report, FieldError{
+				Field:   r.name,
+				Code:    CodeRuleFailed,
+				Message: err.Error(),
+			})
+		}
+	}
+	return report
+}
+
+// DryRun checks data against the form's fields as SetFields would, reporting
+// unknown fields, type mismatches, invalid options and out-of-range numbers,
+// without changing any field values. Entries are checked in name order. Required
+// fields and rules are not checked; use ValidationReport after setting values.
+func (f *PDFForm) DryRun(data map[string]interface{}) []FieldError {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var report []FieldError
+	for _, name := range names {
+		value := data[name]
+		name = f.resolveName(name)
+		field, exists := f.fields[name]
+		if !exists {
+			if !f.options.IgnoreUnknownFields {
+				report = append(report, FieldError{
+					Field:   name,
+					Code:    CodeUnknownField,
+					Message: fmt.Sprintf("field %s not found in form", name),
+				})
+			}
+			continue
+		}
+
+		if f.options.AutoConvert || f.options.ValidationMode == Lenient {
+			converted, err := convertFieldValue(field, value, f.options.LooseOptionMatching)
+			if err != nil {
+				code := CodeTypeMismatch
+				if field.Type == Choice {
+					code = CodeInvalidOption
+				}
+				report = append(report, FieldError{Field: name, Code: code, Message: err.Error()})
+				continue
+			}
+			value = converted
+		}
+
+		// field is a copy, so the form's value is left untouched
+		field.Value = value
+		if fieldErr, ok := f.checkValue(field); !ok {
+			report = append(report, fieldErr)
+		} else if err := f.checkRange(name, value); err != nil {
+			report = append(report, FieldError{
+				Field:   name,
+				Code:    CodeOutOfRange,
+				Message: err.Error(),
+			})
+		}
+	}
+	return report
+}
+
+// checkValue reports whether a field's value matches its type and options,
+// including the checked states a checkbox defines.
+func (f *PDFForm) checkValue(field Field) (FieldError, bool) {
+	switch field.Type {
+	case Text:
+		if _, ok := field.Value.(string); !ok {
+			return FieldError{
+				Field:   field.Name,
+				Code:    CodeTypeMismatch,
+				Message: fmt.Sprintf("field %s requires string value", field.Name),
+			}, false
+		}
+	case Boolean:
+		if _, ok := field.Value.(bool); !ok {
+			return FieldError{
+				Field:   field.Name,
+				Code:    CodeTypeMismatch,
+				Message: fmt.Sprintf("field %s requires boolean value", field.Name),
+			}, false
+		}
+		if err := f.checkOptions(field); err != nil {
+			return FieldError{
+				Field:   field.Name,
+				Code:    CodeInvalidOption,
+				Message: err.Error(),
+			}, false
+		}
+	case Choice:
+		strVal, ok := field.Value.(string)
+		if !ok {
+			return FieldError{
+				Field:   field.Name,
+				Code:    CodeTypeMismatch,
+				Message: fmt.Sprintf("field %s requires string value from options", field.Name),
+			}, false
+		}
+		if _, found := matchOption(strVal, field.Options, f.options.LooseOptionMatching); !found {
+			return FieldError{
+				Field:   field.Name,
+				Code:    CodeInvalidOption,
+				Message: fmt.Sprintf("invalid option for field %s: %s", field.Name, strVal),
+			}, false
+		}
+	}
+	return FieldError{}, true
+}
@@ -0,0 +1,31 @@
+package pdfprocessor
+
+import "testing"
+
+func TestValidationReportCheckboxState(t *testing.T) {
+	f := &PDFForm{
+		fields: map[string]Field{
+			"agree": {Name: "agree", Type: Boolean, Value: true, Options: []string{"On", "Off"}},
+		},
+		order: []string{"agree"},
+		options: Options{
+			BooleanMappings: map[string]BooleanMapping{"agree": {True: "Yes", False: "Off"}},
+		},
+	}
+
+	if err := f.Validate(); err == nil {
+		t.Fatal("Validate() accepted an unsupported checkbox state")
+	}
+	report := f.ValidationReport()
+	if len(report) != 1 || report[0].Field != "agree" || report[0].Code != CodeInvalidOption {
+		t.Fatalf("ValidationReport() = %+v, want one %s error for agree", report, CodeInvalidOption)
+	}
+
+	f.options.BooleanMappings = nil
+	if err := f.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if report := f.ValidationReport(); len(report) != 0 {
+		t.Errorf("ValidationReport() = %+v, want none", report)
+	}
+}
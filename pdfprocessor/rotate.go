@@ -0,0 +1,65 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// rotationNames maps clockwise rotations to pdftk's relative page rotations.
+var rotationNames = map[int]string{
+	90:  "right",
+	180: "down",
+	270: "left",
+}
+
+// RotatePage turns a page of the output clockwise by degrees, a multiple of 90,
+// when the form is saved, e.g. to correct a scanned page. Pages are numbered from
+// 1 in the template, before any SelectPages. Rotating a page again replaces its
+// earlier rotation, and 0 leaves it as it is.
+func (f *PDFForm) RotatePage(page, degrees int) error {
+	if degrees%90 != 0 {
+		return fmt.Errorf("invalid rotation %d: must be a multiple of 90 degrees", degrees)
+	}
+	total, err := f.options.pageCount(context.Background(), f.inputPath)
+	if err != nil {
+		return err
+	}
+	if page < 1 || page > total {
+		return fmt.Errorf("page %d is beyond the document length of %d pages", page, total)
+	}
+
+	degrees = (degrees%360 + 360) % 360
+	if degrees == 0 {
+		delete(f.rotations, page)
+	} else {
+		if f.rotations == nil {
+			f.rotations = make(map[int]int)
+		}
+		f.rotations[page] = degrees
+	}
+	f.pdfData = nil
+	return nil
+}
+
+// rotatePages applies the rotations set with RotatePage to the PDF at path in place.
+func (f *PDFForm) rotatePages(ctx context.Context, path string) error {
+	pages := make([]int, 0, len(f.rotations))
+	for page := range f.rotations {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+
+	err := f.options.rewritePDF(ctx, path, func(in, out string) []string {
+		args := []string{in, "rotate"}
+		for _, page := range pages {
+			args = append(args, strconv.Itoa(page)+rotationNames[f.rotations[page]])
+		}
+		return append(args, "output", out)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rotate pages: %w", err)
+	}
+	return nil
+}
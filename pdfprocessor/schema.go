@@ -0,0 +1,64 @@
+package pdfprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonSchemaDialect is the JSON Schema version produced by JSONSchema
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the top-level JSON Schema document describing a form
+type jsonSchema struct {
+	Schema     string                    `json:"$schema"`
+	Type       string                    `json:"type"`
+	Properties map[string]schemaProperty `json:"properties"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// schemaProperty describes a single field in a JSON Schema document
+type schemaProperty struct {
+	Type      string   `json:"type"`
+	Enum      []string `json:"enum,omitempty"`
+	MaxLength int      `json:"maxLength,omitempty"`
+}
+
+// JSONSchema describes the form's fields as a JSON Schema document, e.g. for
+// generating a data-entry UI. Text and choice fields are strings, with the
+// options of choice fields listed in enum; checkboxes are booleans. Fields made
+// required with SetConditionalRequired are not listed as required.
+func (f *PDFForm) JSONSchema() ([]byte, error) {
+	return buildJSONSchema(f.FieldsInOrder())
+}
+
+// buildJSONSchema encodes the JSON Schema document for fields.
+func buildJSONSchema(fields []Field) ([]byte, error) {
+	schema := jsonSchema{
+		Schema:     jsonSchemaDialect,
+		Type:       "object",
+		Properties: make(map[string]schemaProperty, len(fields)),
+	}
+
+	for _, field := range fields {
+		property := schemaProperty{Type: "string"}
+		switch field.Type {
+		case Boolean:
+			property.Type = "boolean"
+		case Choice:
+			property.Enum = field.Options
+		case Text:
+			property.MaxLength = field.MaxLength
+		}
+		schema.Properties[field.Name] = property
+
+		if field.Required {
+			schema.Required = append(schema.Required, field.Name)
+		}
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON schema: %w", err)
+	}
+	return data, nil
+}
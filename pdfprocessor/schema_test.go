@@ -0,0 +1,71 @@
+package pdfprocessor
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		dump string
+		want string
+	}{
+		{
+			name: "all field types",
+			dump: testDump,
+			want: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"amount": {"type": "string"},
+					"agree": {"type": "boolean"},
+					"country": {"type": "string", "enum": ["MW", "ZA"]},
+					"notes": {"type": "string", "maxLength": 20}
+				},
+				"required": ["name"]
+			}`,
+		},
+		{
+			name: "no required fields",
+			dump: "---\nFieldType: Button\nFieldName: agree\nFieldStateOption: Yes\n",
+			want: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {"agree": {"type": "boolean"}}
+			}`,
+		},
+		{
+			name: "no fields",
+			dump: "",
+			want: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {}
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestForm(t, tt.dump)
+
+			data, err := f.JSONSchema()
+			if err != nil {
+				t.Fatalf("JSONSchema() error = %v", err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("JSONSchema() returned invalid JSON: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("JSONSchema() = %s", data)
+			}
+		})
+	}
+}
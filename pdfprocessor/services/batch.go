@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/josephmowjew/go-form-processor/types"
+)
+
+// BatchItem pairs file data with its upload configuration
+type BatchItem struct {
+	Data   []byte
+	Config types.UploadConfig
+}
+
+// BatchResult holds the outcome of uploading a single BatchItem
+type BatchResult struct {
+	Index    int // Position of the item in the batch
+	Response *types.UploadResponse
+	Err      error
+}
+
+// BatchUpload uploads items using up to concurrency parallel workers. Results are
+// returned in the same order as items. Items not yet started when ctx is cancelled
+// fail with the context's error.
+func BatchUpload(ctx context.Context, uploader Uploader, items []BatchItem, concurrency int) []BatchResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BatchResult, len(items))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = BatchResult{Index: i}
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Response, results[i].Err = uploader.Upload(ctx, items[i].Data, items[i].Config)
+			}
+		}()
+	}
+
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
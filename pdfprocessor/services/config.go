@@ -2,12 +2,37 @@ package service
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/josephmowjew/go-form-processor/types"
 )
 
 // Config holds the service configuration
 type Config struct {
 	UploadBaseURL string
 	BearerToken   string
+	FileFieldName string       // Multipart field name for the uploaded file, defaults to "file"
+	HTTPClient    *http.Client // Client used for uploads, e.g. for proxy configuration
+	Logger        *slog.Logger // Logger for upload events, defaults to slog.Default()
+
+	// ResponseDecoder parses the body of a successful upload response, e.g. to
+	// unwrap a {"data": {...}} envelope. It replaces decoding the body directly as
+	// an UploadResponse; the result is still validated.
+	ResponseDecoder func(body []byte) (*types.UploadResponse, error)
+
+	insecureSkipVerify bool // Set by WithInsecureSkipVerify
+}
+
+// WithInsecureSkipVerify returns a copy of the configuration that disables TLS
+// certificate verification for uploads, logging a warning when the uploader is
+// created. It is meant only for staging or internal endpoints with self-signed
+// certificates; never use it in production. HTTPClient is copied rather than
+// modified, and a client with a transport other than *http.Transport is used
+// unchanged.
+func (c Config) WithInsecureSkipVerify() Config {
+	c.insecureSkipVerify = true
+	return c
 }
 
 // Config validation
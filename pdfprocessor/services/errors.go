@@ -20,3 +20,37 @@ type ErrUpload struct {
 func (e ErrUpload) Error() string {
 	return fmt.Sprintf("upload failed (status %d): %s", e.StatusCode, e.Message)
 }
+
+// ErrInvalidResponse represents a successful upload status whose response body is unusable
+type ErrInvalidResponse struct {
+	StatusCode int
+	Message    string
+	Body       string
+}
+
+func (e ErrInvalidResponse) Error() string {
+	return fmt.Sprintf("invalid upload response (status %d): %s: %s", e.StatusCode, e.Message, e.Body)
+}
+
+// ErrVerification represents a mismatch between an uploaded file and the file retrieved from storage
+type ErrVerification struct {
+	ExpectedSize int64
+	ActualSize   int64
+	ExpectedHash string
+	ActualHash   string
+}
+
+func (e ErrVerification) Error() string {
+	return fmt.Sprintf("upload verification failed: sent %d bytes (sha256 %s), retrieved %d bytes (sha256 %s)",
+		e.ExpectedSize, e.ExpectedHash, e.ActualSize, e.ActualHash)
+}
+
+// ErrContentType represents an upload whose content does not match its filename
+type ErrContentType struct {
+	FileName    string
+	ContentType string
+}
+
+func (e ErrContentType) Error() string {
+	return fmt.Sprintf("file %s is named as a PDF but contains %s", e.FileName, e.ContentType)
+}
@@ -1,14 +1,20 @@
 package service
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/josephmowjew/go-form-processor/types"
 )
@@ -18,43 +24,401 @@ type Uploader interface {
 	Upload(ctx context.Context, data []byte, config types.UploadConfig) (*types.UploadResponse, error)
 }
 
+// MultiUploader is implemented by uploaders that can send several files in one
+// request, including the one returned by NewUploader
+type MultiUploader interface {
+	Uploader
+	UploadMultiple(ctx context.Context, files []NamedData, config types.UploadConfig) ([]*types.UploadResponse, error)
+}
+
+// RequestBuilder is implemented by uploaders that can build an upload request
+// without sending it, including the one returned by NewUploader
+type RequestBuilder interface {
+	BuildRequest(ctx context.Context, data []byte, config types.UploadConfig) (*http.Request, error)
+}
+
+// StreamUploader is implemented by uploaders that can upload a file while it is
+// read, without holding it in memory, including the one returned by NewUploader.
+// Streamed request bodies cannot be replayed, so the client cannot resend them,
+// e.g. to follow a 307 redirect; use Upload when that is needed.
+type StreamUploader interface {
+	UploadStream(ctx context.Context, r io.Reader, config types.UploadConfig) (*types.UploadResponse, error)
+}
+
+// NamedData is a file sent by UploadMultiple
+type NamedData struct {
+	FieldName string // Multipart field name, e.g. "attachment1"
+	FileName  string
+	Data      []byte
+}
+
+// pdfContentType is the MIME type of PDF documents
+const pdfContentType = "application/pdf"
+
+// quoteEscaper escapes quoted strings in multipart headers, as mime/multipart does
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// detectContentType returns the MIME type of data, recognizing PDFs by their header
+func detectContentType(data []byte) string {
+	header := data
+	if len(header) > 1024 {
+		header = header[:1024]
+	}
+	if bytes.Contains(header, []byte("%PDF-")) {
+		return pdfContentType
+	}
+	return http.DetectContentType(data)
+}
+
+// defaultFileFieldName is the multipart field name used when none is configured
+const defaultFileFieldName = "file"
+
 type httpUploader struct {
-	baseURL     string
-	bearerToken string
-	client      *http.Client
+	baseURL       string
+	bearerToken   string
+	fileFieldName string
+	client        *http.Client
+	decode        func(body []byte) (*types.UploadResponse, error)
+	logger        *slog.Logger
 }
 
 // NewUploader creates a new instance of the HTTP uploader with the given configuration.
 func NewUploader(config Config) Uploader {
+	fileFieldName := config.FileFieldName
+	if fileFieldName == "" {
+		fileFieldName = defaultFileFieldName
+	}
+
+	// The default transport honors HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.insecureSkipVerify {
+		client = insecureClient(client, logger)
+	}
+
 	return &httpUploader{
-		baseURL:     config.UploadBaseURL,
-		bearerToken: config.BearerToken,
-		client:      &http.Client{},
+		baseURL:       config.UploadBaseURL,
+		bearerToken:   config.BearerToken,
+		fileFieldName: fileFieldName,
+		client:        client,
+		decode:        config.ResponseDecoder,
+		logger:        logger,
+	}
+}
+
+// insecureClient returns a copy of client that skips TLS certificate verification.
+// Clients with a custom transport other than *http.Transport are returned unchanged.
+func insecureClient(client *http.Client, logger *slog.Logger) *http.Client {
+	base, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		logger.Warn("Cannot disable TLS verification for custom transport", "transport", fmt.Sprintf("%T", client.Transport))
+		return client
+	}
+
+	transport := base.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
 	}
+	transport.TLSClientConfig.InsecureSkipVerify = true
+	logger.Warn("TLS certificate verification is disabled for uploads")
+
+	insecure := *client
+	insecure.Transport = transport
+	return &insecure
 }
 
 // Update the Upload method to return the full response
-func (u *httpUploader) Upload(ctx context.Context, data []byte, config types.UploadConfig) (*types.UploadResponse, error) {
+func (u *httpUploader) Upload(ctx context.Context, data []byte, config types.UploadConfig) (_ *types.UploadResponse, err error) {
+	if err := config.Validate(); err != nil {
+		return nil, &ErrInvalidConfig{Message: err.Error()}
+	}
+
+	u.logger.Info("Uploading file", "file", config.FileName, "organization", config.OrganizationID, "size", len(data))
+	var statusCode int
+	defer u.logOutcome(config, 1, time.Now(), &statusCode, &err)
+
+	files := []NamedData{{FieldName: u.fileFieldName, FileName: config.FileName, Data: data}}
+	respBody, statusCode, err := u.send(ctx, files, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.result(respBody, statusCode)
+}
+
+// UploadStream uploads the file read from r. The multipart body is written to the
+// request through a pipe as r is read, so the file is never held in memory.
+func (u *httpUploader) UploadStream(ctx context.Context, r io.Reader, config types.UploadConfig) (_ *types.UploadResponse, err error) {
 	if err := config.Validate(); err != nil {
 		return nil, &ErrInvalidConfig{Message: err.Error()}
 	}
 
-	log.Printf("Uploading file %s for org %s", config.FileName, config.OrganizationID)
+	// Detect the content type from the start of the file without consuming it
+	reader := bufio.NewReaderSize(r, 1024)
+	header, err := reader.Peek(1024)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file data: %w", err)
+	}
+	contentType := detectContentType(header)
+	if strings.EqualFold(path.Ext(config.FileName), ".pdf") && contentType != pdfContentType {
+		return nil, &ErrContentType{FileName: config.FileName, ContentType: contentType}
+	}
+
+	u.logger.Info("Streaming upload of file", "file", config.FileName, "organization", config.OrganizationID)
+	var statusCode int
+	defer u.logOutcome(config, 1, time.Now(), &statusCode, &err)
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		err := writeFilePart(writer, u.fileFieldName, config.FileName, contentType, reader)
+		if err == nil {
+			err = writeMetadata(writer, config)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := u.newRequest(ctx, pr, writer.FormDataContentType(), config)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+
+	respBody, statusCode, err := u.do(req)
+	// Stop the writer if the request failed before the body was fully read
+	pr.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return u.result(respBody, statusCode)
+}
+
+// result decodes and validates the response to a single file upload
+func (u *httpUploader) result(respBody []byte, statusCode int) (*types.UploadResponse, error) {
+	result, err := u.decodeResponse(respBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some servers report errors in the body of a 200 response
+	if err := result.Validate(); err != nil {
+		return nil, &ErrInvalidResponse{
+			StatusCode: statusCode,
+			Message:    err.Error(),
+			Body:       string(respBody),
+		}
+	}
+
+	return result, nil
+}
+
+// decodeResponse parses a single upload response with the configured decoder or as JSON
+func (u *httpUploader) decodeResponse(respBody []byte) (*types.UploadResponse, error) {
+	if u.decode != nil {
+		result, err := u.decode(respBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w\nResponse body: %s", err, string(respBody))
+		}
+		if result == nil {
+			return nil, fmt.Errorf("failed to decode response: decoder returned no response\nResponse body: %s", string(respBody))
+		}
+		return result, nil
+	}
+
+	// Create new reader from the response body we read
+	var result types.UploadResponse
+	if err := json.NewDecoder(bytes.NewReader(respBody)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w\nResponse body: %s", err, string(respBody))
+	}
+	return &result, nil
+}
 
+// UploadMultiple uploads several files in one multipart request. Files without a
+// field name are sent as the configured file field for the first file and
+// "attachment1", "attachment2", ... for the rest. config.FileName defaults to the
+// name of the first file. The server may answer with an array holding one
+// response per file, in the order sent, or with a single response describing
+// the whole request, which is returned alone; a configured ResponseDecoder always
+// yields a single response. Any other number of responses is an *ErrInvalidResponse.
+func (u *httpUploader) UploadMultiple(ctx context.Context, files []NamedData, config types.UploadConfig) (_ []*types.UploadResponse, err error) {
+	if len(files) == 0 {
+		return nil, &ErrInvalidConfig{Message: "no files to upload"}
+	}
+	if config.FileName == "" {
+		config.FileName = files[0].FileName
+	}
+	if err := config.Validate(); err != nil {
+		return nil, &ErrInvalidConfig{Message: err.Error()}
+	}
+
+	named := make([]NamedData, len(files))
+	for i, file := range files {
+		if file.FieldName == "" {
+			file.FieldName = u.fileFieldName
+			if i > 0 {
+				file.FieldName = fmt.Sprintf("attachment%d", i)
+			}
+		}
+		named[i] = file
+	}
+
+	u.logger.Info("Uploading files", "files", len(files), "organization", config.OrganizationID)
+	var statusCode int
+	defer u.logOutcome(config, len(files), time.Now(), &statusCode, &err)
+
+	respBody, statusCode, err := u.send(ctx, named, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*types.UploadResponse
+	if trimmed := bytes.TrimSpace(respBody); u.decode == nil && len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &results); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w\nResponse body: %s", err, string(respBody))
+		}
+	} else {
+		result, err := u.decodeResponse(respBody)
+		if err != nil {
+			return nil, err
+		}
+		results = []*types.UploadResponse{result}
+	}
+
+	// Responses must map onto the files sent, or describe them all at once
+	if len(results) != len(files) && len(results) != 1 {
+		return nil, &ErrInvalidResponse{
+			StatusCode: statusCode,
+			Message:    fmt.Sprintf("got %d responses for %d files", len(results), len(files)),
+			Body:       string(respBody),
+		}
+	}
+	for _, result := range results {
+		if err := result.Validate(); err != nil {
+			return nil, &ErrInvalidResponse{
+				StatusCode: statusCode,
+				Message:    err.Error(),
+				Body:       string(respBody),
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// BuildRequest builds the request Upload would send for data without sending it,
+// for inspecting the headers and multipart body
+func (u *httpUploader) BuildRequest(ctx context.Context, data []byte, config types.UploadConfig) (*http.Request, error) {
+	if err := config.Validate(); err != nil {
+		return nil, &ErrInvalidConfig{Message: err.Error()}
+	}
+	files := []NamedData{{FieldName: u.fileFieldName, FileName: config.FileName, Data: data}}
+	return u.buildRequest(ctx, files, config)
+}
+
+// send posts files and the upload metadata as a multipart request and returns the
+// body and status code of a successful response
+func (u *httpUploader) send(ctx context.Context, files []NamedData, config types.UploadConfig) ([]byte, int, error) {
+	req, err := u.buildRequest(ctx, files, config)
+	if err != nil {
+		return nil, 0, err
+	}
+	return u.do(req)
+}
+
+// logOutcome logs the result of an upload once it returns. It is deferred before
+// the request is sent, so it takes the status code and error by reference.
+func (u *httpUploader) logOutcome(config types.UploadConfig, files int, start time.Time, statusCode *int, err *error) {
+	attrs := []any{
+		"file", config.FileName,
+		"organization", config.OrganizationID,
+		"files", files,
+		"status", *statusCode,
+		"duration", time.Since(start),
+	}
+	if *err != nil {
+		u.logger.Error("Upload failed", append(attrs, "error", *err)...)
+		return
+	}
+	u.logger.Info("Upload succeeded", attrs...)
+}
+
+// do sends an upload request and returns the body and status code of a
+// successful response, or the status code and an error for any other response
+func (u *httpUploader) do(req *http.Request) ([]byte, int, error) {
+	// Send request
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	u.logger.Debug("Upload response received", "status", resp.StatusCode, "size", len(respBody))
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, resp.StatusCode, fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return respBody, resp.StatusCode, nil
+}
+
+// buildRequest creates the multipart upload request for files and the upload metadata
+func (u *httpUploader) buildRequest(ctx context.Context, files []NamedData, config types.UploadConfig) (*http.Request, error) {
 	// Create multipart form
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	// Add file
-	part, err := writer.CreateFormFile("file", config.FileName)
+	for _, file := range files {
+		// Refuse to label non-PDF content, such as unrendered HTML, as a PDF
+		contentType := detectContentType(file.Data)
+		if strings.EqualFold(path.Ext(file.FileName), ".pdf") && contentType != pdfContentType {
+			return nil, &ErrContentType{FileName: file.FileName, ContentType: contentType}
+		}
+
+		// Add file
+		if err := writeFilePart(writer, file.FieldName, file.FileName, contentType, bytes.NewReader(file.Data)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeMetadata(writer, config); err != nil {
+		return nil, err
+	}
+
+	return u.newRequest(ctx, body, writer.FormDataContentType(), config)
+}
+
+// writeFilePart adds a file read from r to a multipart form
+func writeFilePart(writer *multipart.Writer, fieldName, fileName, contentType string, r io.Reader) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(fieldName), quoteEscaper.Replace(fileName)))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
+		return fmt.Errorf("failed to create form file: %w", err)
 	}
-	if _, err := io.Copy(part, bytes.NewReader(data)); err != nil {
-		return nil, fmt.Errorf("failed to copy file data: %w", err)
+	if _, err := io.Copy(part, r); err != nil {
+		return fmt.Errorf("failed to copy file data: %w", err)
 	}
+	return nil
+}
 
-	// Add metadata
+// writeMetadata adds the upload metadata to a multipart form and closes it
+func writeMetadata(writer *multipart.Writer, config types.UploadConfig) error {
 	metadata := map[string]string{
 		"organizationalId": config.OrganizationID,
 		"branchId":         config.BranchID,
@@ -62,17 +426,21 @@ func (u *httpUploader) Upload(ctx context.Context, data []byte, config types.Upl
 	}
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
 	if err := writer.WriteField("metadata", string(metadataJSON)); err != nil {
-		return nil, fmt.Errorf("failed to write metadata field: %w", err)
+		return fmt.Errorf("failed to write metadata field: %w", err)
 	}
 
 	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
+	return nil
+}
 
+// newRequest creates the upload request for a multipart body
+func (u *httpUploader) newRequest(ctx context.Context, body io.Reader, contentType string, config types.UploadConfig) (*http.Request, error) {
 	// Create request with properly formatted URL - remove /upload from path
 	uploadURL := fmt.Sprintf("%s?organisationalId=%s&branchId=%s&createdBy=%s&authenticate=false",
 		u.baseURL,
@@ -86,34 +454,11 @@ func (u *httpUploader) Upload(ctx context.Context, data []byte, config types.Upl
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Authorization", "Bearer "+u.bearerToken)
-
-	// Send request
-	resp, err := u.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	if config.IdempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", config.IdempotencyKey)
 	}
-	defer resp.Body.Close()
 
-	// Read and log the raw response for debugging
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Log the raw response
-	fmt.Printf("Raw server response: %s\n", string(respBody))
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	// Create new reader from the response body we read
-	var result types.UploadResponse
-	if err := json.NewDecoder(bytes.NewReader(respBody)).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w\nResponse body: %s", err, string(respBody))
-	}
-
-	return &result, nil
+	return req, nil
 }
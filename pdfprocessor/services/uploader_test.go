@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josephmowjew/go-form-processor/types"
+)
+
+// discardLogger drops upload logs in tests.
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// testUploadConfig is valid upload metadata.
+var testUploadConfig = types.UploadConfig{OrganizationID: "org", BranchID: "branch", CreatedBy: "test"}
+
+func TestUploadMultiple(t *testing.T) {
+	files := []NamedData{
+		{FileName: "form.pdf", Data: []byte("%PDF-1.4 form")},
+		{FileName: "id.pdf", Data: []byte("%PDF-1.4 id")},
+		{FileName: "photo.pdf", Data: []byte("%PDF-1.4 photo")},
+	}
+	wantParts := []string{"file", "attachment1", "attachment2"}
+
+	tests := []struct {
+		name      string
+		response  string
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name: "one response per file",
+			response: `[{"fileName":"form.pdf","fileDownloadUri":"/f/1"},
+				{"fileName":"id.pdf","fileDownloadUri":"/f/2"},
+				{"fileName":"photo.pdf","fileDownloadUri":"/f/3"}]`,
+			wantFiles: []string{"form.pdf", "id.pdf", "photo.pdf"},
+		},
+		{
+			name:      "single aggregate object",
+			response:  `{"fileName":"bundle.zip","fileDownloadUri":"/f/bundle"}`,
+			wantFiles: []string{"bundle.zip"},
+		},
+		{
+			name:      "single aggregate array",
+			response:  `[{"fileName":"bundle.zip","fileDownloadUri":"/f/bundle"}]`,
+			wantFiles: []string{"bundle.zip"},
+		},
+		{
+			name: "fewer responses than files",
+			response: `[{"fileName":"form.pdf","fileDownloadUri":"/f/1"},
+				{"fileName":"id.pdf","fileDownloadUri":"/f/2"}]`,
+			wantErr: true,
+		},
+		{
+			name:     "empty array",
+			response: `[]`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reader, err := r.MultipartReader()
+				if err != nil {
+					t.Errorf("request is not multipart: %v", err)
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				var parts []string
+				for {
+					part, err := reader.NextPart()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Errorf("reading part: %v", err)
+						break
+					}
+					if part.FileName() != "" {
+						parts = append(parts, part.FormName())
+					}
+				}
+				if len(parts) != len(wantParts) {
+					t.Errorf("file parts = %v, want %v", parts, wantParts)
+				} else {
+					for i := range parts {
+						if parts[i] != wantParts[i] {
+							t.Errorf("file part %d = %s, want %s", i, parts[i], wantParts[i])
+						}
+					}
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			uploader := NewUploader(Config{UploadBaseURL: server.URL, Logger: discardLogger}).(MultiUploader)
+			results, err := uploader.UploadMultiple(context.Background(), files, testUploadConfig)
+			if tt.wantErr {
+				var invalid *ErrInvalidResponse
+				if !errors.As(err, &invalid) {
+					t.Fatalf("UploadMultiple() error = %v, want *ErrInvalidResponse", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UploadMultiple() error = %v", err)
+			}
+			if len(results) != len(tt.wantFiles) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.wantFiles))
+			}
+			for i, result := range results {
+				if result.FileName != tt.wantFiles[i] {
+					t.Errorf("result %d = %s, want %s", i, result.FileName, tt.wantFiles[i])
+				}
+			}
+		})
+	}
+}
+
+// roundTripperFunc is a custom transport that is not an *http.Transport.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   func() *http.Client
+		insecure bool
+		wantSkip bool   // Whether the uploader's transport skips verification
+		wantLog  string // Warning logged when the uploader is created
+	}{
+		{
+			name:     "default client",
+			client:   func() *http.Client { return nil },
+			insecure: true,
+			wantSkip: true,
+			wantLog:  "TLS certificate verification is disabled",
+		},
+		{
+			name: "custom http.Transport",
+			client: func() *http.Client {
+				return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{ServerName: "storage.internal"}}}
+			},
+			insecure: true,
+			wantSkip: true,
+			wantLog:  "TLS certificate verification is disabled",
+		},
+		{
+			name: "custom round tripper",
+			client: func() *http.Client {
+				return &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}
+			},
+			insecure: true,
+			wantLog:  "Cannot disable TLS verification",
+		},
+		{
+			name:   "not enabled",
+			client: func() *http.Client { return &http.Client{Transport: &http.Transport{}} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			client := tt.client()
+			var original *tls.Config
+			if client != nil {
+				if transport, ok := client.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+					original = transport.TLSClientConfig.Clone()
+				}
+			}
+
+			config := Config{HTTPClient: client, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+			if tt.insecure {
+				config = config.WithInsecureSkipVerify()
+			}
+			uploader := NewUploader(config).(*httpUploader)
+
+			skip := false
+			if transport, ok := uploader.client.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+				skip = transport.TLSClientConfig.InsecureSkipVerify
+			}
+			if skip != tt.wantSkip {
+				t.Errorf("InsecureSkipVerify = %v, want %v", skip, tt.wantSkip)
+			}
+			if tt.wantLog == "" && logs.Len() > 0 {
+				t.Errorf("unexpected log output: %s", logs.String())
+			}
+			if !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("log output %q does not contain %q", logs.String(), tt.wantLog)
+			}
+
+			// The configured client and its transport are left alone
+			if client == nil {
+				return
+			}
+			if transport, ok := client.Transport.(*http.Transport); ok {
+				if tt.insecure && tt.wantSkip && uploader.client.Transport == client.Transport {
+					t.Error("uploader shares the configured transport instead of a clone")
+				}
+				if transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify {
+					t.Error("configured transport was modified")
+				}
+				if original != nil && transport.TLSClientConfig.ServerName != original.ServerName {
+					t.Error("configured TLS config was modified")
+				}
+			} else if uploader.client != client {
+				t.Error("client with a custom round tripper was replaced")
+			}
+		})
+	}
+}
+
+func TestWithInsecureSkipVerifySelfSignedServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"fileName":"form.pdf","fileDownloadUri":"/f/1"}`))
+	}))
+	defer server.Close()
+
+	config := Config{UploadBaseURL: server.URL, Logger: discardLogger}
+	upload := testUploadConfig
+	upload.FileName = "form.pdf"
+	data := []byte("%PDF-1.4 form")
+
+	if _, err := NewUploader(config).Upload(context.Background(), data, upload); err == nil {
+		t.Fatal("Upload() to a self-signed server succeeded with verification enabled")
+	}
+	if _, err := NewUploader(config.WithInsecureSkipVerify()).Upload(context.Background(), data, upload); err != nil {
+		t.Fatalf("Upload() with WithInsecureSkipVerify error = %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package pdfprocessor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// structTag is the struct tag key used to map struct fields to form fields
+const structTag = "pdf"
+
+// SetStruct sets field values from a struct whose fields carry `pdf:"fieldName"` tags.
+// Values are converted with ConvertFieldValue before being set. Untagged fields and
+// fields tagged `pdf:"-"` are ignored, and `pdf:"name,omitempty"` skips zero values.
+func (f *PDFForm) SetStruct(v interface{}) []error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return []error{fmt.Errorf("SetStruct requires a non-nil struct")}
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return []error{fmt.Errorf("SetStruct requires a struct, got %T", v)}
+	}
+
+	var errs []error
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		name, omitEmpty, ok := parseStructTag(sf)
+		if !ok {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		actualName := name
+		if _, exists := f.fields[name]; !exists {
+			var found bool
+			if actualName, found = f.FindMatchingField(name); !found {
+				if f.options.IgnoreUnknownFields {
+					f.skipUnknownField(name)
+				} else {
+					errs = append(errs, fmt.Errorf("field '%s' not found", name))
+				}
+				continue
+			}
+		}
+
+		value, err := f.ConvertFieldValue(actualName, fv.Interface())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field '%s': %w", name, err))
+			continue
+		}
+		if err := f.SetField(actualName, value); err != nil {
+			errs = append(errs, fmt.Errorf("field '%s': %w", name, err))
+		}
+	}
+	return errs
+}
+
+// ScanStruct reads the current field values into a pointer to a struct whose fields
+// carry `pdf:"fieldName"` tags. String values are converted to the struct field's
+// type, so "42" fills an int and "On" fills a bool. Fields without a value are left untouched.
+func (f *PDFForm) ScanStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ScanStruct requires a non-nil pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+
+	var errors []string
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		name, _, ok := parseStructTag(sf)
+		if !ok {
+			continue
+		}
+
+		field, exists := f.fields[name]
+		if !exists {
+			actualName, found := f.FindMatchingField(name)
+			if !found {
+				continue
+			}
+			field = f.fields[actualName]
+		}
+		if field.Value == nil {
+			continue
+		}
+
+		if err := assignValue(rv.Field(i), field.Value); err != nil {
+			errors = append(errors, fmt.Sprintf("field '%s': %v", name, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to scan some fields: %s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// parseStructTag returns the form field name and options for a struct field.
+func parseStructTag(sf reflect.StructField) (name string, omitEmpty bool, ok bool) {
+	if !sf.IsExported() {
+		return "", false, false
+	}
+	tag, exists := sf.Tag.Lookup(structTag)
+	if !exists || tag == "-" {
+		return "", false, false
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	if parts[0] == "" {
+		return "", false, false
+	}
+	return parts[0], omitEmpty, true
+}
+
+// assignValue stores a field value in a struct field, converting between types as needed.
+func assignValue(dst reflect.Value, value interface{}) error {
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	str := strings.TrimSpace(fmt.Sprint(value))
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(fmt.Sprint(value))
+	case reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if _, err := fmt.Sscan(str, &n); err != nil {
+			return fmt.Errorf("cannot convert %q to %s", str, dst.Type())
+		}
+		if dst.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s", n, dst.Type())
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if _, err := fmt.Sscan(str, &n); err != nil {
+			return fmt.Errorf("cannot convert %q to %s", str, dst.Type())
+		}
+		if dst.OverflowUint(n) {
+			return fmt.Errorf("value %d overflows %s", n, dst.Type())
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if _, err := fmt.Sscan(str, &n); err != nil {
+			return fmt.Errorf("cannot convert %q to %s", str, dst.Type())
+		}
+		dst.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported struct field type %s", dst.Type())
+	}
+	return nil
+}
+
+// parseBool interprets checkbox and textual boolean values.
+func parseBool(value interface{}) (bool, error) {
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(fmt.Sprint(value))) {
+	case "true", "yes", "1", "on":
+		return true, nil
+	case "false", "no", "0", "off", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean value: %v", value)
+}
@@ -0,0 +1,174 @@
+package pdfprocessor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetStruct(t *testing.T) {
+	type applicant struct {
+		Name    string `pdf:"name"`
+		Amount  int    `pdf:"amount"`
+		Agree   bool   `pdf:"agree"`
+		Country string `pdf:"country,omitempty"`
+		Notes   string `pdf:"-"`
+		Email   string
+	}
+	type unknown struct {
+		Name  string `pdf:"name"`
+		Phone string `pdf:"phone"`
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		value      interface{}
+		wantValues map[string]interface{}
+		wantErrs   []string
+	}{
+		{
+			name:       "converts tagged fields",
+			value:      applicant{Name: "Ada", Amount: 42, Agree: true, Country: "ZA", Notes: "skipped", Email: "skipped"},
+			wantValues: map[string]interface{}{"name": "Ada", "amount": "42", "agree": true, "country": "ZA"},
+		},
+		{
+			name:       "pointer with omitempty zero value",
+			value:      &applicant{Name: "Ada"},
+			wantValues: map[string]interface{}{"name": "Ada", "amount": "0", "agree": false},
+		},
+		{
+			name:       "invalid option",
+			value:      applicant{Name: "Ada", Country: "US"},
+			wantValues: map[string]interface{}{"name": "Ada", "amount": "0", "agree": false},
+			wantErrs:   []string{"field 'country'"},
+		},
+		{
+			name:       "unknown field",
+			value:      unknown{Name: "Ada", Phone: "555"},
+			wantValues: map[string]interface{}{"name": "Ada"},
+			wantErrs:   []string{"field 'phone' not found"},
+		},
+		{
+			name:       "unknown field ignored",
+			opts:       []Option{WithIgnoreUnknownFields()},
+			value:      unknown{Name: "Ada", Phone: "555"},
+			wantValues: map[string]interface{}{"name": "Ada"},
+		},
+		{
+			name:       "not a struct",
+			value:      map[string]string{"name": "Ada"},
+			wantValues: map[string]interface{}{},
+			wantErrs:   []string{"requires a struct"},
+		},
+		{
+			name:       "nil pointer",
+			value:      (*applicant)(nil),
+			wantValues: map[string]interface{}{},
+			wantErrs:   []string{"non-nil struct"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestForm(t, testDump, tt.opts...)
+
+			errs := f.SetStruct(tt.value)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("SetStruct() errors = %v, want %d", errs, len(tt.wantErrs))
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tt.wantErrs[i]) {
+					t.Errorf("error %d = %q, want it to contain %q", i, err, tt.wantErrs[i])
+				}
+			}
+			if got := f.Values(); !reflect.DeepEqual(got, tt.wantValues) {
+				t.Errorf("Values() = %v, want %v", got, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestScanStruct(t *testing.T) {
+	type result struct {
+		Name    string  `pdf:"name"`
+		Amount  int     `pdf:"amount"`
+		Ratio   float64 `pdf:"notes"`
+		Agree   bool    `pdf:"agree"`
+		Country string  `pdf:"country"`
+		Skipped string  `pdf:"-"`
+	}
+	type small struct {
+		Amount int8 `pdf:"amount"`
+	}
+	type textBool struct {
+		Subscribed bool `pdf:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		dst     interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{
+			name:   "converts values",
+			values: map[string]interface{}{"name": "Ada", "amount": "42", "notes": "0.5", "agree": true, "country": "ZA"},
+			dst:    &result{Skipped: "kept"},
+			want:   &result{Name: "Ada", Amount: 42, Ratio: 0.5, Agree: true, Country: "ZA", Skipped: "kept"},
+		},
+		{
+			name:   "unset fields left untouched",
+			values: map[string]interface{}{"name": "Ada"},
+			dst:    &result{Amount: 7, Country: "MW"},
+			want:   &result{Name: "Ada", Amount: 7, Country: "MW"},
+		},
+		{
+			name:   "checkbox state text",
+			values: map[string]interface{}{"name": "On"},
+			dst:    &textBool{},
+			want:   &textBool{Subscribed: true},
+		},
+		{
+			name:    "not a number",
+			values:  map[string]interface{}{"amount": "lots"},
+			dst:     &result{},
+			want:    &result{},
+			wantErr: `field 'amount': cannot convert "lots" to int`,
+		},
+		{
+			name:    "overflow",
+			values:  map[string]interface{}{"amount": "300"},
+			dst:     &small{},
+			want:    &small{},
+			wantErr: "overflows int8",
+		},
+		{
+			name:    "not a pointer",
+			dst:     result{},
+			want:    result{},
+			wantErr: "non-nil pointer to a struct",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestForm(t, testDump)
+			if err := f.SetFields(tt.values); err != nil {
+				t.Fatal(err)
+			}
+
+			err := f.ScanStruct(tt.dst)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ScanStruct() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ScanStruct() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Errorf("ScanStruct() filled %+v, want %+v", tt.dst, tt.want)
+			}
+		})
+	}
+}
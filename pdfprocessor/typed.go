@@ -0,0 +1,29 @@
+package pdfprocessor
+
+import "fmt"
+
+// SetFieldTyped sets a field like SetField, but first checks that T is the Go
+// type the field holds: string for text and choice fields, bool for checkboxes.
+// Unlike SetField with WithAutoConvert, it never converts the value.
+func SetFieldTyped[T any](f *PDFForm, name string, v T) error {
+	name = f.resolveName(name)
+	field, exists := f.fields[name]
+	if !exists {
+		return fmt.Errorf("field %s not found", name)
+	}
+
+	var ok bool
+	var want string
+	switch field.Type {
+	case Boolean:
+		_, ok = any(v).(bool)
+		want = "bool"
+	default:
+		_, ok = any(v).(string)
+		want = "string"
+	}
+	if !ok {
+		return fmt.Errorf("field %s requires a %s value, got %T", name, want, v)
+	}
+	return f.SetField(name, v)
+}
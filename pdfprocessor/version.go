@@ -0,0 +1,52 @@
+package pdfprocessor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pdftkVersionPattern matches the version in the banner of both the legacy pdftk
+// ("pdftk 2.02 a Handy Tool...") and pdftk-java ("pdftk port to java 3.3.3 a Handy Tool...").
+var pdftkVersionPattern = regexp.MustCompile(`pdftk (?:port to java )?(\d+(?:\.\d+)+)`)
+
+// pdftkVersionCheck makes sure the version warning is logged at most once per process.
+var pdftkVersionCheck sync.Once
+
+// PDFTKVersion runs pdftk --version and returns its version number, e.g. "3.3.3".
+// Versions 3 and later are pdftk-java; earlier ones are the legacy C++ pdftk.
+func PDFTKVersion() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dependencyProbeTimeout)
+	defer cancel()
+
+	output, err := Options{}.runCommand(ctx, "pdftk", "--version")
+	if err != nil {
+		return "", err
+	}
+	match := pdftkVersionPattern.FindStringSubmatch(string(output))
+	if match == nil {
+		return "", fmt.Errorf("unrecognized pdftk version output: %s", strings.TrimSpace(string(output)))
+	}
+	return match[1], nil
+}
+
+// warnPDFTKVersion logs a warning, once, if the installed pdftk is the legacy
+// version, whose field dumps differ from pdftk-java's, e.g. in how non-ASCII
+// text is encoded, and whose flattening is less reliable.
+func (o Options) warnPDFTKVersion() {
+	pdftkVersionCheck.Do(func() {
+		version, err := PDFTKVersion()
+		if err != nil {
+			return
+		}
+		major, _ := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+		if major < 3 {
+			o.logEvent(slog.LevelWarn, "Legacy pdftk detected; field dumps and flattening may differ from pdftk-java",
+				"version", version)
+		}
+	})
+}
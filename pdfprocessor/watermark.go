@@ -0,0 +1,153 @@
+package pdfprocessor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// watermark describes a stamp applied to every page of the output.
+type watermark struct {
+	stampPath  string  // PDF whose first page is used as the stamp
+	text       string  // Text to render when no stamp PDF is given
+	background bool    // Whether the stamp is placed behind the page content
+	opacity    float64 // Opacity of text watermarks, from 0 to 1
+	fontSize   float64 // Font size of text watermarks in points
+}
+
+// WatermarkOption configures a watermark.
+type WatermarkOption func(*watermark)
+
+// WithWatermarkBackground places the watermark behind the page content instead of on top.
+// Note that opaque page backgrounds will hide a background watermark.
+func WithWatermarkBackground() WatermarkOption {
+	return func(w *watermark) {
+		w.background = true
+	}
+}
+
+// WithWatermarkOpacity sets the opacity of a text watermark, from 0 (invisible) to 1 (solid).
+// Stamp PDFs keep whatever transparency they were created with.
+func WithWatermarkOpacity(opacity float64) WatermarkOption {
+	return func(w *watermark) {
+		w.opacity = opacity
+	}
+}
+
+// WithWatermarkFontSize sets the font size of a text watermark in points.
+func WithWatermarkFontSize(size float64) WatermarkOption {
+	return func(w *watermark) {
+		w.fontSize = size
+	}
+}
+
+// ApplyWatermark stamps the first page of stampPDF onto every page of the output when the form is saved.
+func (f *PDFForm) ApplyWatermark(stampPDF string, opts ...WatermarkOption) error {
+	stampPDF, err := pdftkPath(stampPDF)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(stampPDF); err != nil {
+		return fmt.Errorf("watermark file: %w", err)
+	}
+
+	w := &watermark{stampPath: stampPDF}
+	for _, opt := range opts {
+		opt(w)
+	}
+	f.watermark = w
+	f.pdfData = nil
+	return nil
+}
+
+// ApplyTextWatermark stamps text such as "DRAFT" diagonally across every page of the
+// output when the form is saved. Text is rendered in Helvetica, so it is limited to
+// characters in the standard Latin character set.
+func (f *PDFForm) ApplyTextWatermark(text string, opts ...WatermarkOption) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("watermark text is empty")
+	}
+
+	w := &watermark{
+		text:     text,
+		opacity:  0.3,
+		fontSize: 72,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.opacity < 0 || w.opacity > 1 {
+		return fmt.Errorf("watermark opacity must be between 0 and 1, got %v", w.opacity)
+	}
+	f.watermark = w
+	f.pdfData = nil
+	return nil
+}
+
+// applyWatermark stamps the configured watermark onto the PDF at path.
+func (f *PDFForm) applyWatermark(ctx context.Context, path string) error {
+	stampPath := f.watermark.stampPath
+	if stampPath == "" {
+		stampFile, err := os.CreateTemp("", "watermark-*.pdf")
+		if err != nil {
+			return fmt.Errorf("failed to create watermark file: %w", err)
+		}
+		defer f.options.removeTemp(stampFile.Name())
+
+		_, err = stampFile.Write(textWatermarkPDF(f.watermark.text, f.watermark.fontSize, f.watermark.opacity))
+		stampFile.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write watermark file: %w", err)
+		}
+		stampPath = stampFile.Name()
+	}
+
+	operation := "stamp"
+	if f.watermark.background {
+		operation = "background"
+	}
+	return f.options.rewritePDF(ctx, path, func(in, out string) []string {
+		return []string{in, operation, stampPath, "output", out}
+	})
+}
+
+// textWatermarkPDF builds a single letter-sized page with text drawn diagonally across it.
+func textWatermarkPDF(text string, fontSize, opacity float64) []byte {
+	escaped := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(text)
+
+	// Approximate Helvetica's average glyph width to center the text on the diagonal
+	width := float64(len(text)) * fontSize * 0.55
+	content := fmt.Sprintf("q /GS1 gs 0.5 g BT /F1 %.2f Tf 0.7071 0.7071 -0.7071 0.7071 306 396 Tm %.2f %.2f Td (%s) Tj ET Q",
+		fontSize, -width/2, -fontSize/3, escaped)
+
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> /ExtGState << /GS1 5 0 R >> >> /Contents 6 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Type /ExtGState /ca %.2f /CA %.2f >>", opacity, opacity),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	})
+}
+
+// buildPDF writes a PDF file from object bodies numbered from 1, the first being
+// the document catalog.
+func buildPDF(objects []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
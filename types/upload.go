@@ -8,6 +8,7 @@ type UploadConfig struct {
 	OrganizationID string
 	BranchID       string
 	CreatedBy      string
+	IdempotencyKey string // Sent as the Idempotency-Key header when set; reuse it when retrying an upload
 }
 
 // Validate checks if the upload configuration is valid
@@ -34,3 +35,14 @@ type UploadResponse struct {
 	FileType        string `json:"fileType"`
 	Size            int64  `json:"size"`
 }
+
+// Validate checks if the upload response describes a retrievable file
+func (r UploadResponse) Validate() error {
+	if r.FileDownloadUri == "" {
+		return fmt.Errorf("file download URI is missing")
+	}
+	if r.FileName == "" {
+		return fmt.Errorf("filename is missing")
+	}
+	return nil
+}